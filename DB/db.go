@@ -12,7 +12,9 @@ import (
 
 var Db *gorm.DB
 
-func InitDatabase() {
+// InitDatabase connects and migrates the schema, then runs each backfill once
+// to fill in data that new columns and tables need for existing rows.
+func InitDatabase(backfills ...func() error) {
 	err := godotenv.Load(".env")
 	if err != nil {
 		log.Fatal("Error loading .env", err)
@@ -27,6 +29,8 @@ func InitDatabase() {
 		&models.Address{},
 		&models.Admin{},
 		&models.Category{},
+		&models.Product{},
+		&models.ReviewRating{},
 		&models.Order{},
 		&models.OrderItem{},
 		&models.Coupon{},
@@ -36,10 +40,38 @@ func InitDatabase() {
 		&models.Wallet{},
 		&models.Offer{},
 		&models.TempOrder{},
+		&models.TempOrderItem{},
 		&models.WalletTransaction{},
+		&models.Notification{},
+		&models.ProductVariant{},
+		&models.Cart{},
+		&models.ProductImage{},
+		&models.CategoryAttribute{},
+		&models.ProductAffinity{},
+		&models.ProductView{},
+		&models.ProductQuestion{},
+		&models.ProductAnswer{},
+		&models.AnswerVote{},
+		&models.Bundle{},
+		&models.BundleComponent{},
+		&models.SlugRedirect{},
+		&models.PriceHistory{},
+		&models.Brand{},
+		&models.SearchTerm{},
+		&models.CompareItem{},
+		&models.StockReservation{},
+		&models.StockMovement{},
 	)
 	if Autoerr != nil {
 		log.Fatalf("Migration failed: %v", err)
 	}
+	if err := migrateSearch(); err != nil {
+		log.Fatalf("Search migration failed: %v", err)
+	}
+	for _, backfill := range backfills {
+		if err := backfill(); err != nil {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+	}
 
 }
@@ -0,0 +1,45 @@
+package db
+
+// searchSetup keeps products.search_vector in sync with the product name
+// (weight A), its category name (weight B) and its description (weight C).
+// Renaming a category touches its products so their vectors are rebuilt.
+var searchSetup = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING gin (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING gin (product_name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_search_terms_prefix ON search_terms (term text_pattern_ops)`,
+	`CREATE OR REPLACE FUNCTION products_search_vector() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('english', coalesce(NEW.product_name, '')), 'A') ||
+			setweight(to_tsvector('english', coalesce((SELECT category_name FROM categories WHERE category_id = NEW.category_id), '')), 'B') ||
+			setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS products_search_vector_update ON products`,
+	`CREATE TRIGGER products_search_vector_update
+		BEFORE INSERT OR UPDATE OF product_name, description, category_id ON products
+		FOR EACH ROW EXECUTE FUNCTION products_search_vector()`,
+	`CREATE OR REPLACE FUNCTION categories_search_vector() RETURNS trigger AS $$
+	BEGIN
+		UPDATE products SET product_name = product_name WHERE category_id = NEW.category_id;
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS categories_search_vector_update ON categories`,
+	`CREATE TRIGGER categories_search_vector_update
+		AFTER UPDATE OF category_name ON categories
+		FOR EACH ROW EXECUTE FUNCTION categories_search_vector()`,
+	`UPDATE products SET product_name = product_name WHERE search_vector IS NULL`,
+}
+
+func migrateSearch() error {
+	for _, statement := range searchSetup {
+		if err := Db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
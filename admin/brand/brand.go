@@ -0,0 +1,128 @@
+package brand
+
+import (
+	"net/http"
+	"strings"
+
+	db "admin/DB"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ListBrands returns every brand with the number of products linked to it.
+func ListBrands(c *gin.Context) {
+	var brands []struct {
+		models.Brand
+		Products int `json:"products"`
+	}
+	if err := db.Db.Model(&models.Brand{}).
+		Select(`brands.*, (SELECT COUNT(*) FROM products p
+			WHERE p.brand_id = brands.brand_id AND p.deleted_at IS NULL) AS products`).
+		Order("name ASC").Scan(&brands).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch brands"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"brands": brands})
+}
+
+func AddBrand(c *gin.Context) {
+	var input models.BrandInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var brand models.Brand
+	applyBrandInput(&brand, input)
+	if brand.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brand name is required"})
+		return
+	}
+	if nameTaken(brand) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Brand already exists"})
+		return
+	}
+
+	if err := db.Db.Create(&brand).Error; err != nil {
+		log.WithFields(log.Fields{
+			"Name":  brand.Name,
+			"error": err,
+		}).Error("Cannot create brand")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create brand"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Brand added successfully", "brand": brand})
+}
+
+func UpdateBrand(c *gin.Context) {
+	var brand models.Brand
+	if err := db.Db.Where("brand_id = ?", c.Param("id")).First(&brand).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	var input models.BrandInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyBrandInput(&brand, input)
+	if brand.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brand name is required"})
+		return
+	}
+	if nameTaken(brand) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Brand already exists"})
+		return
+	}
+
+	if err := db.Db.Save(&brand).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update brand"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Brand updated successfully", "brand": brand})
+}
+
+// DeleteBrand refuses to remove a brand that live products still point to;
+// reassign or clear their brand first.
+func DeleteBrand(c *gin.Context) {
+	var brand models.Brand
+	if err := db.Db.Where("brand_id = ?", c.Param("id")).First(&brand).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	var products int64
+	db.Db.Model(&models.Product{}).Where("brand_id = ?", brand.BrandID).Count(&products)
+	if products > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Brand still has products", "products": products})
+		return
+	}
+
+	if err := db.Db.Delete(&brand).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete brand"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Brand deleted successfully"})
+}
+
+func applyBrandInput(brand *models.Brand, input models.BrandInput) {
+	brand.Name = strings.TrimSpace(input.Name)
+	brand.LogoURL = strings.TrimSpace(input.LogoURL)
+	brand.Description = strings.TrimSpace(input.Description)
+	brand.Country = strings.TrimSpace(input.Country)
+}
+
+func nameTaken(brand models.Brand) bool {
+	var count int64
+	db.Db.Model(&models.Brand{}).
+		Where("LOWER(name) = LOWER(?) AND brand_id <> ?", brand.Name, brand.BrandID).Count(&count)
+	return count > 0
+}
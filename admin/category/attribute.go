@@ -0,0 +1,158 @@
+package category
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+var attributeName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ListAttributes returns the attribute schema products of the category must
+// follow, including attributes inherited from its ancestors.
+func ListAttributes(c *gin.Context) {
+	var category models.Category
+	if err := db.Db.Where("category_id = ?", c.Param("id")).First(&category).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	categories, err := helper.LoadCategoryIndex()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load categories"})
+		return
+	}
+	schema, err := categories.AttributeSchema(db.Db, category.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load attributes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attributes": schema})
+}
+
+func AddAttribute(c *gin.Context) {
+	var category models.Category
+	if err := db.Db.Where("category_id = ?", c.Param("id")).First(&category).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	var input models.AttributeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attribute := models.CategoryAttribute{CategoryID: category.CategoryID}
+	if message := applyAttributeInput(&attribute, input); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	var count int64
+	db.Db.Model(&models.CategoryAttribute{}).
+		Where("category_id = ? AND name = ?", category.CategoryID, attribute.Name).Count(&count)
+	if count != 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Attribute already exists"})
+		return
+	}
+
+	if err := db.Db.Create(&attribute).Error; err != nil {
+		log.WithFields(log.Fields{
+			"CategoryID": category.CategoryID,
+			"Name":       attribute.Name,
+			"error":      err,
+		}).Error("Cannot create attribute")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create attribute"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Attribute added successfully", "attribute": attribute})
+}
+
+func UpdateAttribute(c *gin.Context) {
+	var attribute models.CategoryAttribute
+	if err := db.Db.Where("attribute_id = ?", c.Param("id")).First(&attribute).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attribute not found"})
+		return
+	}
+
+	var input models.AttributeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Renaming or retyping would orphan the values already stored on products.
+	if strings.ToLower(strings.TrimSpace(input.Name)) != attribute.Name || input.Type != attribute.Type {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attribute name and type cannot be changed"})
+		return
+	}
+
+	if message := applyAttributeInput(&attribute, input); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	if err := db.Db.Save(&attribute).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update attribute"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attribute updated successfully", "attribute": attribute})
+}
+
+func DeleteAttribute(c *gin.Context) {
+	var attribute models.CategoryAttribute
+	if err := db.Db.Where("attribute_id = ?", c.Param("id")).First(&attribute).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attribute not found"})
+		return
+	}
+
+	if err := db.Db.Delete(&attribute).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attribute"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attribute deleted successfully"})
+}
+
+func applyAttributeInput(attribute *models.CategoryAttribute, input models.AttributeInput) string {
+	attribute.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	if !attributeName.MatchString(attribute.Name) {
+		return "Attribute name must be lowercase letters, digits and underscores"
+	}
+
+	attribute.Label = strings.TrimSpace(input.Label)
+	attribute.Type = input.Type
+	attribute.Required = input.Required
+	attribute.Unit = ""
+	attribute.Options = nil
+
+	switch input.Type {
+	case helper.AttributeNumber:
+		attribute.Unit = strings.TrimSpace(input.Unit)
+	case helper.AttributeEnum:
+		seen := map[string]bool{}
+		for _, option := range input.Options {
+			option = strings.TrimSpace(option)
+			if option == "" || seen[strings.ToLower(option)] {
+				continue
+			}
+			seen[strings.ToLower(option)] = true
+			attribute.Options = append(attribute.Options, option)
+		}
+		if len(attribute.Options) == 0 {
+			return "Enum attributes need at least one option"
+		}
+	}
+	return ""
+}
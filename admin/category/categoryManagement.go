@@ -1,12 +1,15 @@
 package category
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
 	db "admin/DB"
+	"admin/helper"
 	"admin/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func ViewCategory(c *gin.Context) {
@@ -45,17 +48,24 @@ func AddCategory(c *gin.Context) {
 		return
 	}
 
-	var existingCategory models.Category
-	if err := db.Db.Where("category_name = ?", category.CategoryName).First(&existingCategory).Error; err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Category already exists"})
+	if message, status := validateParent(&category); message != "" {
+		c.JSON(status, gin.H{"error": message})
 		return
 	}
+
+	slug, err := helper.PickSlug(db.Db, helper.SlugCategory, category.Slug, category.CategoryName, 0)
+	if err != nil {
+		slugError(c, err)
+		return
+	}
+	category.Slug = slug
+
 	if err := db.Db.Create(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create category"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"Category created successfully": category.CategoryName})
+	c.JSON(http.StatusCreated, gin.H{"Category created successfully": category.CategoryName, "category_id": category.CategoryID, "slug": category.Slug})
 }
 
 func EditCategory(c *gin.Context) {
@@ -66,20 +76,53 @@ func EditCategory(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
 		return
 	}
+	oldName, oldSlug := category.CategoryName, category.Slug
 	if err := c.ShouldBind(&category); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input "})
 		return
 	}
-	if err := db.Db.Save(&category).Error; err != nil {
+	if message, status := validateParent(&category); message != "" {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	// Renamed categories get a slug from the new name unless one was sent.
+	slug := oldSlug
+	if category.Slug != oldSlug || category.CategoryName != oldName {
+		requested := category.Slug
+		if requested == oldSlug {
+			requested = ""
+		}
+		var err error
+		if slug, err = helper.PickSlug(db.Db, helper.SlugCategory, requested, category.CategoryName, category.CategoryID); err != nil {
+			slugError(c, err)
+			return
+		}
+	}
+	category.Slug = oldSlug
+
+	if err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&category).Error; err != nil {
+			return err
+		}
+		return helper.ChangeSlug(tx, helper.SlugCategory, int(category.CategoryID), oldSlug, slug)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"Category updated successfully": category.CategoryName})
+	c.JSON(http.StatusOK, gin.H{"Category updated successfully": category.CategoryName, "slug": slug})
 }
 
+// DeleteCategory moves a category and its products to the trash, from where
+// RestoreCategory can bring them back. What happens to child categories
+// depends on the subtree query parameter:
+//
+//	lift (default)  children move up to the deleted category's parent
+//	cascade         every descendant category and its products is deleted too
 func DeleteCategory(c *gin.Context) {
 	categoryID := c.Param("id")
+	subtree := c.DefaultQuery("subtree", "lift")
 
 	var category models.Category
 
@@ -88,15 +131,98 @@ func DeleteCategory(c *gin.Context) {
 		return
 	}
 
-	if err := db.Db.Model(&category).Update("deleted_at", time.Now()).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
+	if subtree != "lift" && subtree != "cascade" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subtree must be lift or cascade"})
 		return
 	}
 
-	if err := db.Db.Model(&models.Product{}).Where("category_id = ?", categoryID).Update("deleted_at", time.Now()).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete associated products"})
+	ids := []uint{category.CategoryID}
+	if subtree == "cascade" {
+		var err error
+		if ids, err = helper.DescendantIDs(db.Db, category.CategoryID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load subcategories"})
+			return
+		}
+	}
+
+	now := time.Now()
+	var trashedProducts int64
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if subtree == "lift" {
+			if err := tx.Model(&models.Category{}).Where("parent_id = ?", category.CategoryID).
+				Update("parent_id", category.ParentID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Category{}).Where("category_id IN ?", ids).Update("deleted_at", now).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.Product{}).Where("category_id IN ?", ids).Update("deleted_at", now)
+		trashedProducts = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "Category deleted successfully",
+		"deleted_categories": ids,
+		"trashed_products":   trashedProducts,
+	})
+}
+
+// validateParent checks that the parent exists, that no sibling has the same
+// name and that the move would not make the category its own ancestor.
+func validateParent(category *models.Category) (string, int) {
+	if category.ParentID != nil && *category.ParentID == 0 {
+		category.ParentID = nil
+	}
+
+	siblings := db.Db.Model(&models.Category{}).
+		Where("category_name = ? AND category_id <> ?", category.CategoryName, category.CategoryID)
+	if category.ParentID == nil {
+		siblings = siblings.Where("parent_id IS NULL")
+	} else {
+		siblings = siblings.Where("parent_id = ?", *category.ParentID)
+	}
+	var count int64
+	siblings.Count(&count)
+	if count != 0 {
+		return "Category already exists", http.StatusConflict
+	}
+
+	if category.ParentID == nil {
+		return "", 0
+	}
+
+	var parent models.Category
+	if err := db.Db.First(&parent, *category.ParentID).Error; err != nil {
+		return "Parent category not found", http.StatusBadRequest
+	}
+
+	if category.CategoryID != 0 {
+		descendants, err := helper.DescendantIDs(db.Db, category.CategoryID)
+		if err != nil {
+			return "Failed to load subcategories", http.StatusInternalServerError
+		}
+		for _, id := range descendants {
+			if id == parent.CategoryID {
+				return "A category cannot be moved under itself or its subcategories", http.StatusBadRequest
+			}
+		}
+	}
+	return "", 0
+}
+
+func slugError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, helper.ErrInvalidSlug):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, helper.ErrSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not assign slug"})
+	}
 }
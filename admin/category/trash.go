@@ -0,0 +1,113 @@
+package category
+
+import (
+	"net/http"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ListTrashedCategories shows soft-deleted categories with the number of
+// products that were trashed along with each of them.
+func ListTrashedCategories(c *gin.Context) {
+	page, limit, offset := helper.Paginate(c)
+
+	query := db.Db.Unscoped().Model(&models.Category{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch trash"})
+		return
+	}
+
+	var categories []struct {
+		models.Category
+		TrashedProducts int `json:"trashed_products"`
+	}
+	if err := query.Select(`categories.*, (
+			SELECT COUNT(*) FROM products p
+			WHERE p.category_id = categories.category_id AND p.deleted_at = categories.deleted_at
+		) AS trashed_products`).
+		Order("deleted_at DESC").Limit(limit).Offset(offset).Scan(&categories).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+		"page":       page,
+		"limit":      limit,
+		"total":      total,
+	})
+}
+
+// RestoreCategory brings back a trashed category and any subcategories that
+// were deleted in the same cascade. With ?products=true the products deleted
+// together with those categories are restored as well; products trashed
+// separately beforehand stay in the trash.
+func RestoreCategory(c *gin.Context) {
+	var category models.Category
+	if err := db.Db.Unscoped().Where("category_id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&category).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found in trash"})
+		return
+	}
+
+	if message, status := validateParent(&category); message != "" {
+		if status == http.StatusBadRequest {
+			message = "The parent category is deleted, restore it first"
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+
+	var ids []uint
+	var restoredProducts int64
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Raw(`
+			WITH RECURSIVE tree AS (
+				SELECT category_id FROM categories WHERE category_id = ?
+				UNION
+				SELECT c.category_id FROM categories c
+				JOIN tree t ON c.parent_id = t.category_id
+				WHERE c.deleted_at = ?
+			)
+			SELECT category_id FROM tree
+		`, category.CategoryID, category.DeletedAt).Scan(&ids).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Category{}).Where("category_id IN ?", ids).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if c.Query("products") != "true" {
+			return nil
+		}
+
+		result := tx.Unscoped().Model(&models.Product{}).
+			Where("category_id IN ? AND deleted_at = ?", ids, category.DeletedAt).
+			Update("deleted_at", nil)
+		restoredProducts = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"CategoryID": category.CategoryID,
+			"error":      err,
+		}).Error("Cannot restore category")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore category"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Category restored successfully",
+		"restored_categories": ids,
+		"restored_products":   restoredProducts,
+	})
+}
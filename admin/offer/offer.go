@@ -4,7 +4,10 @@ import (
 	"net/http"
 
 	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
 	"admin/models"
+	"admin/notify"
 
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -46,6 +49,13 @@ func AddOffer(c *gin.Context) {
 		return
 	}
 
+	if input.VariantID != 0 {
+		if _, err := helper.FindVariant(db.Db, input.ProductID, input.VariantID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Cannot find variant"})
+			return
+		}
+	}
+
 	if input.OfferPercentage == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Please enter an offer amount"})
 		return
@@ -62,11 +72,20 @@ func AddOffer(c *gin.Context) {
 
 	NewOffer := models.Offer{
 		ProductID:       input.ProductID,
+		VariantID:       input.VariantID,
 		OfferPercentage: input.OfferPercentage,
 	}
 
 	db.Db.Create(&NewOffer)
 
+	// offer_discount on the product only reflects the product-wide offer.
+	recordOfferPrice(c, input.ProductID)
+
+	if input.VariantID != 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Offer updated successfully"})
+		return
+	}
+
 	if err := db.Db.Model(&product).Where("product_id = ?", input.ProductID).
 		Update("offer_discount", input.OfferPercentage).Error; err != nil {
 		log.WithFields(log.Fields{
@@ -77,6 +96,8 @@ func AddOffer(c *gin.Context) {
 		return
 	}
 
+	notifyOfferPriceDrop(product, 0, input.OfferPercentage)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Offer updated successfully"})
 }
 
@@ -98,6 +119,13 @@ func UpdateOffer(c *gin.Context) {
 		return
 	}
 
+	if input.VariantID != 0 {
+		if _, err := helper.FindVariant(db.Db, input.ProductID, input.VariantID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Cannot find variant"})
+			return
+		}
+	}
+
 	if input.OfferPercentage < 0 || input.OfferPercentage > 100 {
 		log.WithFields(log.Fields{
 			"ProductID":   input.ProductID,
@@ -107,7 +135,10 @@ func UpdateOffer(c *gin.Context) {
 		return
 	}
 
-	if err := db.Db.Model(&offer).Where("product_id = ?", input.ProductID).
+	db.Db.Where("product_id = ? AND variant_id = ?", input.ProductID, input.VariantID).First(&offer)
+	oldPercentage := offer.OfferPercentage
+
+	if err := db.Db.Model(&models.Offer{}).Where("product_id = ? AND variant_id = ?", input.ProductID, input.VariantID).
 		Update("offer_percentage", input.OfferPercentage).Error; err != nil {
 		log.WithFields(log.Fields{
 			"ProductID":   input.ProductID,
@@ -117,6 +148,13 @@ func UpdateOffer(c *gin.Context) {
 		return
 	}
 
+	recordOfferPrice(c, input.ProductID)
+
+	if input.VariantID != 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Offer updated successfully"})
+		return
+	}
+
 	if err := db.Db.Model(&product).Where("product_id = ?", input.ProductID).
 		Update("offer_discount", input.OfferPercentage).Error; err != nil {
 		log.WithFields(log.Fields{
@@ -127,6 +165,31 @@ func UpdateOffer(c *gin.Context) {
 		return
 	}
 
+	notifyOfferPriceDrop(product, oldPercentage, input.OfferPercentage)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Offer updated successfully"})
 
 }
+
+// recordOfferPrice logs the price after an offer change. The offer itself is
+// already saved, so a failure here is only logged.
+func recordOfferPrice(c *gin.Context, productID int) {
+	if err := helper.RecordPrice(db.Db, productID, middleware.CurrentID(c), helper.PriceSourceOffer); err != nil {
+		log.WithFields(log.Fields{
+			"ProductID": productID,
+			"error":     err,
+		}).Error("Cannot record price history")
+	}
+}
+
+func notifyOfferPriceDrop(product models.Product, oldPercentage, newPercentage int) {
+	if newPercentage <= oldPercentage {
+		return
+	}
+	helper.NotifyWishlist(product.ProductID, notify.EventPriceDrop, gin.H{
+		"ProductID":   product.ProductID,
+		"ProductName": product.ProductName,
+		"OldPrice":    product.Price * float64(100-oldPercentage) / 100,
+		"NewPrice":    product.Price * float64(100-newPercentage) / 100,
+	})
+}
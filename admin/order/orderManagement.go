@@ -5,9 +5,15 @@ import (
 	"net/http"
 
 	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
 	"admin/models"
 	"admin/models/responsemodels"
+	"admin/notify"
+
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 func ListOrders(c *gin.Context) {
@@ -95,31 +101,84 @@ func ChangeOrderStatus(c *gin.Context) {
 		return
 	}
 
-	if input.Status == "Canceled" && order.Status != "Canceled" {
-		var product models.Product
-		var item models.OrderItem
-		if err := db.Db.First(&product, item.ProductID).Error; err == nil {
-			product.Quantity += order.Quantity
-			if err := db.Db.Save(&product).Error; err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product quantity"})
-				return
-			}
-		}
-	} else {
-		if order.Status == "Canceled" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Order is already canceled"})
-			return
-		} else if order.Status == "Delivered" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot cancel a delivered order"})
+	if input.Status == "Canceled" {
+		// Only orders that have not shipped still hold their stock; returned
+		// orders were restocked when they came back.
+		if order.Status != "Pending" && order.Status != "Processing" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot cancel an order that is " + order.Status})
 			return
 		}
+	} else if order.Status == "Canceled" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order is already canceled"})
+		return
+	} else if order.Status == "Delivered" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot cancel a delivered order"})
+		return
 	}
 
-	order.Status = input.Status
-	if err := db.Db.Save(&order).Error; err != nil {
+	previous := order.Status
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if input.Status == "Canceled" {
+			var items []models.OrderItem
+			if err := tx.Where("order_id = ?", order.OrderID).Find(&items).Error; err != nil {
+				return err
+			}
+			for _, item := range items {
+				if err := helper.AdjustStock(tx, item.ProductID, item.VariantID, item.Quantity, helper.StockChange{
+					Reason:  helper.StockOrderCanceled,
+					OrderID: order.OrderID,
+					AdminID: middleware.CurrentID(c),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		order.Status = input.Status
+		return tx.Save(&order).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
 		return
 	}
 
+	// Saving the same status again must not mail the customer twice.
+	if order.Status != previous {
+		notifyStatusChange(order)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Order status updated successfully", "new_status": order.Status})
 }
+
+func notifyStatusChange(order models.Order) {
+	userID := uint(order.UserID)
+	data := gin.H{"OrderID": order.OrderID}
+
+	switch order.Status {
+	case "Shipped":
+		helper.NotifyUser(userID, notify.EventOrderShipped, data)
+	case "Delivered":
+		var attachments []notify.Attachment
+		invoice, err := helper.BuildInvoice(order.OrderID)
+		if err == nil {
+			var pdfBytes []byte
+			pdfBytes, err = helper.GeneratePDF(invoice)
+			if err == nil {
+				attachments = append(attachments, notify.Attachment{
+					Filename:    invoice.InvoiceID + ".pdf",
+					ContentType: "application/pdf",
+					Data:        pdfBytes,
+				})
+			}
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"OrderID": order.OrderID,
+				"error":   err,
+			}).Error("cannot attach invoice to delivery mail")
+		}
+		data["HasInvoice"] = len(attachments) > 0
+		helper.NotifyUser(userID, notify.EventOrderDelivered, data, attachments...)
+	case "Returned":
+		helper.NotifyUser(userID, notify.EventReturnAccepted, data)
+	}
+}
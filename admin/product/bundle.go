@@ -0,0 +1,147 @@
+package product
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ListBundles(c *gin.Context) {
+	var bundles []models.Bundle
+	if err := db.Db.Preload("Components").Order("bundle_id ASC").Find(&bundles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch bundles"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bundles": bundles})
+}
+
+func AddBundle(c *gin.Context) {
+	var input models.BundleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bundle := models.Bundle{}
+	if message := applyBundleInput(&bundle, input); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	if err := db.Db.Create(&bundle).Error; err != nil {
+		log.WithFields(log.Fields{
+			"Name":  bundle.Name,
+			"error": err,
+		}).Error("Cannot create bundle")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create bundle"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Bundle added successfully", "bundle": bundle})
+}
+
+// UpdateBundle replaces the bundle's details and its full component list.
+func UpdateBundle(c *gin.Context) {
+	var bundle models.Bundle
+	if err := db.Db.Where("bundle_id = ?", c.Param("id")).First(&bundle).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found"})
+		return
+	}
+
+	var input models.BundleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if message := applyBundleInput(&bundle, input); message != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": message})
+		return
+	}
+
+	// Carts hold the old component split, so the bundle is taken out of them.
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", bundle.BundleID).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("bundle_id = ?", bundle.BundleID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(&bundle).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bundle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle updated successfully", "bundle": bundle})
+}
+
+func DeleteBundle(c *gin.Context) {
+	var bundle models.Bundle
+	if err := db.Db.Where("bundle_id = ?", c.Param("id")).First(&bundle).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found"})
+		return
+	}
+
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_id = ?", bundle.BundleID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&bundle).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bundle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle deleted successfully"})
+}
+
+func applyBundleInput(bundle *models.Bundle, input models.BundleInput) string {
+	bundle.Name = strings.TrimSpace(input.Name)
+	bundle.Description = input.Description
+	bundle.Price = input.Price
+	bundle.ImgURL = input.ImgURL
+	bundle.Components = nil
+
+	seen := map[[2]int]bool{}
+	for _, component := range input.Components {
+		key := [2]int{component.ProductID, component.VariantID}
+		if seen[key] {
+			return fmt.Sprintf("Product %d is listed twice, combine the quantities", component.ProductID)
+		}
+		seen[key] = true
+
+		var product models.Product
+		if err := db.Db.First(&product, component.ProductID).Error; err != nil {
+			return fmt.Sprintf("Product %d not found", component.ProductID)
+		}
+
+		var hasVariants int64
+		db.Db.Model(&models.ProductVariant{}).Where("product_id = ?", product.ProductID).Count(&hasVariants)
+		if hasVariants != 0 && component.VariantID == 0 {
+			return fmt.Sprintf("Product %d has variants, please choose one", product.ProductID)
+		}
+		if component.VariantID != 0 {
+			if _, err := helper.FindVariant(db.Db, product.ProductID, component.VariantID); err != nil {
+				return fmt.Sprintf("Variant %d not found for product %d", component.VariantID, product.ProductID)
+			}
+		}
+
+		bundle.Components = append(bundle.Components, models.BundleComponent{
+			ProductID: component.ProductID,
+			VariantID: component.VariantID,
+			Quantity:  component.Quantity,
+		})
+	}
+	return ""
+}
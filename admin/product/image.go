@@ -0,0 +1,228 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+	"admin/storage"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const maxImageSize = 10 << 20
+
+func ListImages(c *gin.Context) {
+	var images []models.ProductImage
+	if err := db.Db.Where("product_id = ?", c.Param("id")).Order("position ASC, image_id ASC").Find(&images).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch images"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"images": images})
+}
+
+// UploadImages accepts one or more files in the "images" form field, stores a
+// thumbnail, medium and large rendition of each and appends them to the
+// product's gallery.
+func UploadImages(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["images"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please upload at least one file in the images field"})
+		return
+	}
+
+	var position int
+	db.Db.Model(&models.ProductImage{}).Where("product_id = ?", product.ProductID).
+		Select("COALESCE(MAX(position), -1) + 1").Scan(&position)
+
+	// Every file is read and resized before any is stored, so a bad file
+	// rejects the whole upload instead of leaving part of it behind.
+	renditions := make([]map[string][]byte, 0, len(form.File["images"]))
+	for _, fileHeader := range form.File["images"] {
+		if fileHeader.Size > maxImageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is larger than 10MB", fileHeader.Filename)})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot read " + fileHeader.Filename})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+		file.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot read " + fileHeader.Filename})
+			return
+		}
+
+		resized, err := helper.ResizeImage(data)
+		if err != nil {
+			if errors.Is(err, helper.ErrUnsupportedImage) || errors.Is(err, helper.ErrImageTooLarge) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fileHeader.Filename + ": " + err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot decode " + fileHeader.Filename})
+			return
+		}
+		renditions = append(renditions, resized)
+	}
+
+	var uploaded []models.ProductImage
+	for i, resized := range renditions {
+		image, err := storeImage(c.Request.Context(), product.ProductID, position+i, resized)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"ProductID": product.ProductID,
+				"file":      form.File["images"][i].Filename,
+				"error":     err,
+			}).Error("Cannot store product image")
+			for _, stored := range uploaded {
+				removeImage(stored)
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot store " + form.File["images"][i].Filename + ", no images were added"})
+			return
+		}
+		uploaded = append(uploaded, image)
+	}
+
+	if product.ImgURL == "" && len(uploaded) > 0 {
+		db.Db.Model(&product).Update("img_url", uploaded[0].MediumURL)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Images uploaded successfully", "images": uploaded})
+}
+
+func storeImage(ctx context.Context, productID, position int, renditions map[string][]byte) (models.ProductImage, error) {
+	image := models.ProductImage{
+		ProductID:  productID,
+		Position:   position,
+		StorageKey: fmt.Sprintf("products/%d/%d", productID, time.Now().UnixNano()),
+	}
+
+	urls := map[string]*string{
+		"thumbnail": &image.ThumbnailURL,
+		"medium":    &image.MediumURL,
+		"large":     &image.LargeURL,
+	}
+	for name, data := range renditions {
+		url, err := storage.Default.Put(ctx, image.StorageKey+"/"+name+".jpg", data, "image/jpeg")
+		if err != nil {
+			deleteRenditions(image.StorageKey)
+			return image, err
+		}
+		*urls[name] = url
+	}
+
+	if err := db.Db.Create(&image).Error; err != nil {
+		deleteRenditions(image.StorageKey)
+		return image, err
+	}
+	return image, nil
+}
+
+func ReorderImages(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var input struct {
+		ImageIDs []int `json:"image_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	db.Db.Model(&models.ProductImage{}).Where("product_id = ?", productID).Count(&count)
+	if int(count) != len(input.ImageIDs) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "image_ids must list every image of the product exactly once"})
+		return
+	}
+
+	err = db.Db.Transaction(func(tx *gorm.DB) error {
+		for position, imageID := range input.ImageIDs {
+			result := tx.Model(&models.ProductImage{}).
+				Where("image_id = ? AND product_id = ?", imageID, productID).
+				Update("position", position)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return gorm.ErrRecordNotFound
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "image_ids must list every image of the product exactly once"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot reorder images"})
+		return
+	}
+
+	var first models.ProductImage
+	if err := db.Db.Where("product_id = ?", productID).Order("position ASC").First(&first).Error; err == nil {
+		db.Db.Model(&models.Product{}).Where("product_id = ?", productID).Update("img_url", first.MediumURL)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Images reordered successfully"})
+}
+
+func DeleteImage(c *gin.Context) {
+	var image models.ProductImage
+	if err := db.Db.Where("image_id = ?", c.Param("id")).First(&image).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	if err := db.Db.Delete(&image).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot delete image"})
+		return
+	}
+	deleteRenditions(image.StorageKey)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted successfully"})
+}
+
+// removeImage undoes storeImage.
+func removeImage(image models.ProductImage) {
+	if err := db.Db.Unscoped().Delete(&image).Error; err != nil {
+		log.WithFields(log.Fields{
+			"ImageID": image.ImageID,
+			"error":   err,
+		}).Warn("Cannot delete image record")
+	}
+	deleteRenditions(image.StorageKey)
+}
+
+func deleteRenditions(storageKey string) {
+	for name := range helper.ImageSizes {
+		if err := storage.Default.Delete(context.Background(), storageKey+"/"+name+".jpg"); err != nil {
+			log.WithFields(log.Fields{
+				"key":   storageKey,
+				"error": err,
+			}).Warn("Cannot delete stored image")
+		}
+	}
+}
@@ -0,0 +1,461 @@
+package product
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+const maxImportSize = 5 << 20
+
+var importColumns = []string{"sku", "name", "description", "price", "category", "quantity", "image_url"}
+
+type importRow struct {
+	Row    int      `json:"row"`
+	SKU    string   `json:"sku,omitempty"`
+	Name   string   `json:"name,omitempty"`
+	Action string   `json:"action"`
+	Errors []string `json:"errors,omitempty"`
+
+	product models.Product
+}
+
+// ImportProducts creates or updates products from an uploaded CSV or XLSX
+// sheet. Rows whose SKU matches an existing product update it; every other
+// valid row creates a new product. With ?dry_run=true nothing is written.
+func ImportProducts(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please upload a CSV or XLSX file"})
+		return
+	}
+	if header.Size > maxImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File exceeds the 5MB limit"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot read file"})
+		return
+	}
+	defer file.Close()
+
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".csv":
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		records, err = reader.ReadAll()
+	case ".xlsx":
+		records, err = readSheet(file)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only .csv and .xlsx files are supported"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot parse file: " + err.Error()})
+		return
+	}
+	if len(records) < 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File has no product rows"})
+		return
+	}
+
+	columns, err := mapColumns(records[0])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	categories, err := helper.LoadCategoryIndex()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot load categories"})
+		return
+	}
+
+	var rows []importRow
+	seen := map[string]int{}
+	for i, record := range records[1:] {
+		if isBlank(record) {
+			continue
+		}
+		row := parseRow(i+2, record, columns, categories)
+		if row.SKU != "" {
+			if first, ok := seen[strings.ToLower(row.SKU)]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("duplicate sku, first used on row %d", first))
+			} else {
+				seen[strings.ToLower(row.SKU)] = row.Row
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if err := planRows(rows, categories); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot look up existing products"})
+		return
+	}
+
+	var created, updated, failed int
+	for _, row := range rows {
+		switch row.Action {
+		case "create":
+			created++
+		case "update":
+			updated++
+		default:
+			failed++
+		}
+	}
+
+	if !dryRun {
+		if err := db.Db.Transaction(func(tx *gorm.DB) error {
+			return applyRows(tx, rows, middleware.CurrentID(c))
+		}); err != nil {
+			log.WithFields(log.Fields{
+				"file":  header.Filename,
+				"error": err,
+			}).Error("Product import failed")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Import failed, no products were changed"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"summary": gin.H{"created": created, "updated": updated, "failed": failed},
+		"rows":    rows,
+	})
+}
+
+// ExportProducts downloads the catalog in the same layout ImportProducts
+// accepts. ?format=xlsx returns a spreadsheet, anything else CSV.
+func ExportProducts(c *gin.Context) {
+	var products []models.Product
+	if err := db.Db.Order("product_id ASC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot retrieve products"})
+		return
+	}
+
+	categories, err := helper.LoadCategoryIndex()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot load categories"})
+		return
+	}
+
+	records := [][]string{importColumns}
+	for _, product := range products {
+		var sku string
+		if product.SKU != nil {
+			sku = *product.SKU
+		}
+		records = append(records, []string{
+			sku,
+			product.ProductName,
+			product.Description,
+			strconv.FormatFloat(product.Price, 'f', 2, 64),
+			categories.Path(product.CategoryID),
+			strconv.Itoa(product.Quantity),
+			product.ImgURL,
+		})
+	}
+
+	if c.Query("format") == "xlsx" {
+		data, err := writeSheet(records)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot generate spreadsheet"})
+			return
+		}
+		c.Header("Content-Disposition", "attachment; filename=products.xlsx")
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", data)
+		return
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.WriteAll(records); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot generate CSV"})
+		return
+	}
+	c.Header("Content-Disposition", "attachment; filename=products.csv")
+	c.Data(http.StatusOK, "text/csv", buf.Bytes())
+}
+
+func readSheet(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("workbook has no sheets")
+	}
+	return f.GetRows(sheets[0])
+}
+
+func writeSheet(records [][]string) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	for i, record := range records {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]any, len(record))
+		for j, value := range record {
+			values[j] = value
+		}
+		if err := f.SetSheetRow("Sheet1", cell, &values); err != nil {
+			return nil, err
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func mapColumns(header []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.ReplaceAll(name, " ", "_")
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "price", "category", "quantity"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+	return columns, nil
+}
+
+func parseRow(number int, record []string, columns map[string]int, categories helper.CategoryIndex) importRow {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := importRow{Row: number, SKU: field("sku"), Name: field("name")}
+	product := models.Product{
+		ProductName: row.Name,
+		Description: field("description"),
+		ImgURL:      field("image_url"),
+	}
+	if row.SKU != "" {
+		sku := row.SKU
+		product.SKU = &sku
+	}
+
+	if product.ProductName == "" {
+		row.Errors = append(row.Errors, "name is required")
+	}
+
+	price, err := strconv.ParseFloat(field("price"), 64)
+	if err != nil {
+		row.Errors = append(row.Errors, "price must be a number")
+	} else if price < 0 {
+		row.Errors = append(row.Errors, "price cannot be negative")
+	}
+	product.Price = price
+
+	quantity, err := strconv.Atoi(field("quantity"))
+	if err != nil {
+		row.Errors = append(row.Errors, "quantity must be a whole number")
+	} else if quantity < 0 {
+		row.Errors = append(row.Errors, "quantity cannot be negative")
+	}
+	product.Quantity = quantity
+
+	categoryID, err := categories.Resolve(field("category"))
+	if err != nil {
+		row.Errors = append(row.Errors, err.Error())
+	}
+	product.CategoryID = categoryID
+
+	if product.ImgURL != "" {
+		if u, err := url.ParseRequestURI(product.ImgURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			row.Errors = append(row.Errors, "image_url must be an http(s) URL")
+		}
+	}
+
+	row.product = product
+	return row
+}
+
+// planRows decides whether each valid row creates or updates a product. The
+// sheet carries no attributes, so rows whose category requires some that the
+// product does not have yet are rejected.
+func planRows(rows []importRow, categories helper.CategoryIndex) error {
+	var skus []string
+	for _, row := range rows {
+		if row.SKU != "" {
+			skus = append(skus, strings.ToLower(row.SKU))
+		}
+	}
+
+	// SKUs match case-insensitively, as they do within the sheet. Trashed
+	// products still own their SKU, so they are looked up too.
+	existing := map[string]models.Product{}
+	ambiguous := map[string]bool{}
+	if len(skus) > 0 {
+		var products []models.Product
+		if err := db.Db.Unscoped().Where("LOWER(sku) IN ?", skus).Find(&products).Error; err != nil {
+			return err
+		}
+		for _, product := range products {
+			key := strings.ToLower(*product.SKU)
+			if _, ok := existing[key]; ok {
+				ambiguous[key] = true
+			}
+			existing[key] = product
+		}
+	}
+
+	schemas := map[uint][]models.CategoryAttribute{}
+	for i := range rows {
+		key := strings.ToLower(rows[i].SKU)
+		if current, ok := existing[key]; ok && len(rows[i].Errors) == 0 {
+			if ambiguous[key] {
+				rows[i].Errors = append(rows[i].Errors, "sku matches more than one product")
+			} else if current.DeletedAt.Valid {
+				rows[i].Errors = append(rows[i].Errors, "sku belongs to a product in the trash, restore it first")
+			}
+		}
+
+		if len(rows[i].Errors) == 0 {
+			var values map[string]any
+			if current, ok := existing[key]; ok {
+				values = current.Attributes
+			}
+			schema, ok := schemas[rows[i].product.CategoryID]
+			if !ok {
+				var err error
+				if schema, err = categories.AttributeSchema(db.Db, rows[i].product.CategoryID); err != nil {
+					return err
+				}
+				schemas[rows[i].product.CategoryID] = schema
+			}
+			attributes, problems := helper.ValidateAttributes(schema, values)
+			rows[i].Errors = append(rows[i].Errors, problems...)
+			rows[i].product.Attributes = attributes
+		}
+
+		if len(rows[i].Errors) > 0 {
+			rows[i].Action = "error"
+			continue
+		}
+		if current, ok := existing[key]; ok {
+			rows[i].product.ProductID = current.ProductID
+			rows[i].Action = "update"
+		} else {
+			rows[i].Action = "create"
+		}
+	}
+	return nil
+}
+
+// applyRows writes the rows planRows accepted. Rows with errors are only
+// reported, so the valid rows of a sheet still go through.
+func applyRows(tx *gorm.DB, rows []importRow, adminID uint) error {
+	for _, row := range importable(rows) {
+		product := row.product
+		switch row.Action {
+		case "create":
+			slug, err := helper.UniqueSlug(tx, helper.SlugProduct, helper.Slugify(product.ProductName), 0)
+			if err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			product.Slug = slug
+			if err := tx.Create(&product).Error; err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			if err := helper.RecordStock(tx, product.ProductID, 0, product.Quantity, helper.StockChange{
+				Reason:  helper.StockImport,
+				AdminID: adminID,
+			}); err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+		case "update":
+			var current models.Product
+			if err := tx.Select("product_id, product_name, slug").First(&current, product.ProductID).Error; err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			if current.ProductName != product.ProductName {
+				slug, err := helper.UniqueSlug(tx, helper.SlugProduct, helper.Slugify(product.ProductName), current.ProductID)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", row.Row, err)
+				}
+				if err := helper.ChangeSlug(tx, helper.SlugProduct, current.ProductID, current.Slug, slug); err != nil {
+					return fmt.Errorf("row %d: %w", row.Row, err)
+				}
+			}
+			if err := tx.Model(&models.Product{}).Where("product_id = ?", product.ProductID).Updates(map[string]any{
+				"product_name": product.ProductName,
+				"description":  product.Description,
+				"price":        product.Price,
+				"category_id":  product.CategoryID,
+				"img_url":      product.ImgURL,
+			}).Error; err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+			if _, err := helper.SetStock(tx, product.ProductID, 0, product.Quantity, helper.StockChange{
+				Reason:  helper.StockImport,
+				AdminID: adminID,
+			}); err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+		}
+		if err := helper.RecordPrice(tx, product.ProductID, adminID, helper.PriceSourceImport); err != nil {
+			return fmt.Errorf("row %d: %w", row.Row, err)
+		}
+	}
+	return nil
+}
+
+// importable returns the rows that create or update a product.
+func importable(rows []importRow) []importRow {
+	var valid []importRow
+	for _, row := range rows {
+		if row.Action == "create" || row.Action == "update" {
+			valid = append(valid, row)
+		}
+	}
+	return valid
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
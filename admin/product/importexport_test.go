@@ -0,0 +1,65 @@
+package product
+
+import (
+	"testing"
+
+	"admin/helper"
+)
+
+func TestImportableSkipsErrorRows(t *testing.T) {
+	categories := helper.CategoryIndex{1: {CategoryID: 1, CategoryName: "Tables"}}
+	columns, err := mapColumns(importColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := [][]string{
+		{"OAK-1", "Oak Table", "Solid oak", "12000", "Tables", "4", ""},
+		{"PINE-1", "Pine Table", "", "not a price", "Tables", "2", ""},
+		{"TEAK-1", "Teak Table", "", "18000", "Tables", "1", ""},
+	}
+	rows := make([]importRow, len(records))
+	for i, record := range records {
+		rows[i] = parseRow(i+2, record, columns, categories)
+	}
+	if len(rows[1].Errors) == 0 {
+		t.Fatal("row 3 should not parse")
+	}
+
+	// As planned for SKUs not in the catalog yet, with TEAK-1 updating an
+	// existing product.
+	rows[0].Action = "create"
+	rows[1].Action = "error"
+	rows[2].Action = "update"
+	rows[2].product.ProductID = 7
+
+	tests := []struct {
+		name string
+		rows []importRow
+		want []string
+	}{
+		{"valid and invalid rows", rows, []string{"OAK-1", "TEAK-1"}},
+		{"only invalid rows", rows[1:2], nil},
+		{"no rows", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := importable(tt.rows)
+			if len(got) != len(tt.want) {
+				t.Fatalf("importable() returned %d rows, want %d", len(got), len(tt.want))
+			}
+			for i, row := range got {
+				if row.SKU != tt.want[i] {
+					t.Errorf("row %d has sku %q, want %q", i, row.SKU, tt.want[i])
+				}
+				if row.product.ProductName == "" {
+					t.Errorf("row %d lost its product", i)
+				}
+			}
+		})
+	}
+
+	if got := importable(rows); got[0].product.Price != 12000 || got[1].product.ProductID != 7 {
+		t.Errorf("importable() changed the rows: %+v", got)
+	}
+}
@@ -0,0 +1,52 @@
+package product
+
+import (
+	"net/http"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceHistory lists the logged prices of a product, newest first. Pass
+// variant_id to see a variant's prices instead of the product's.
+func PriceHistory(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Unscoped().Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	page, limit, offset := helper.Paginate(c)
+	query := db.Db.Model(&models.PriceHistory{}).
+		Where("product_id = ? AND variant_id = ?", product.ProductID, c.DefaultQuery("variant_id", "0"))
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch price history"})
+		return
+	}
+
+	var history []models.PriceHistory
+	if err := query.Order("changed_at DESC, price_history_id DESC").Limit(limit).Offset(offset).Find(&history).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch price history"})
+		return
+	}
+
+	lowest, err := helper.LowestPrices([]int{product.ProductID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch price history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product_id":           product.ProductID,
+		"lowest_price_30_days": lowest[product.ProductID],
+		"history":              history,
+		"page":                 page,
+		"limit":                limit,
+		"total":                total,
+	})
+}
@@ -1,26 +1,38 @@
 package product
 
 import (
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
 	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
 	"admin/models"
+	"admin/notify"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func ViewProducts(c *gin.Context) {
 	var products []models.Product
-	result := db.Db.Order("product_id ASC").Find(&products)
+	query := db.Db.Order("product_id ASC")
+	if lifecycle := c.Query("lifecycle"); lifecycle != "" {
+		query = query.Where("lifecycle = ?", lifecycle)
+	}
+	result := query.Find(&products)
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
 	}
 
+	// Status is availability only and is worked out for the response; the
+	// lifecycle decides whether shoppers see the product at all.
 	for i := range products {
 		if products[i].Quantity == 0 {
 			products[i].Status = 2 // Out of stock
-			db.Db.Model(&products[i]).Update("status", products[i].Status)
 		} else {
 			products[i].Status = 1 // Available
 		}
@@ -47,13 +59,58 @@ func AddProducts(c *gin.Context) {
 		return
 	}
 
-	// Determine initial status
-	products.Status = 1 // Assume Available by default
-	if products.Quantity == 0 {
-		products.Status = 2 // Out of stock
+	if products.SKU != nil && strings.TrimSpace(*products.SKU) == "" {
+		products.SKU = nil
+	}
+
+	brandID, ok := checkBrand(products.BrandID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brand not found"})
+		return
+	}
+	products.BrandID = brandID
+
+	attributes, problems, err := validateProductAttributes(products.CategoryID, products.Attributes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load category attributes"})
+		return
 	}
+	if len(problems) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attributes", "details": problems})
+		return
+	}
+	products.Attributes = attributes
 
-	if err := db.Db.Create(&products).Error; err != nil {
+	if products.Lifecycle == "" {
+		products.Lifecycle = helper.LifecycleActive
+	}
+	publishAt, err := helper.CheckLifecycle(products.Lifecycle, products.PublishAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	products.PublishAt = publishAt
+
+	slug, err := helper.PickSlug(db.Db, helper.SlugProduct, products.Slug, products.ProductName, 0)
+	if err != nil {
+		slugError(c, err)
+		return
+	}
+	products.Slug = slug
+
+	if err := db.Db.Transaction(func(tx *gorm.DB) error {
+		// Availability follows the stock, so a posted status is ignored.
+		if err := tx.Omit("status").Create(&products).Error; err != nil {
+			return err
+		}
+		if err := helper.RecordStock(tx, products.ProductID, 0, products.Quantity, helper.StockChange{
+			Reason:  helper.StockInitial,
+			AdminID: middleware.CurrentID(c),
+		}); err != nil {
+			return err
+		}
+		return helper.RecordPrice(tx, products.ProductID, middleware.CurrentID(c), helper.PriceSourceManual)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create product"})
 		return
 	}
@@ -71,11 +128,13 @@ func UpdateProduct(c *gin.Context) {
 	}
 
 	var input struct {
-		ProductName string  `json:"product_name"`
-		Description string  `json:"description"`
-		Price       float64 `json:"price"`
-		ImgURL      string  `json:"img_url"`
-		Status      int     `json:"status"` // Changed to int
+		ProductName string         `json:"product_name"`
+		Description string         `json:"description"`
+		Price       float64        `json:"price"`
+		ImgURL      string         `json:"img_url"`
+		Attributes  map[string]any `json:"attributes"`
+		Slug        string         `json:"slug"`
+		BrandID     *uint          `json:"brand_id"` // 0 clears the brand
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -88,15 +147,103 @@ func UpdateProduct(c *gin.Context) {
 		Description: input.Description,
 		Price:       input.Price,
 		ImgURL:      input.ImgURL,
-		Status:      input.Status,
 	}
 
-	if err := db.Db.Model(&product).Updates(updates).Error; err != nil {
+	brandID, ok := checkBrand(input.BrandID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	if input.Attributes != nil {
+		attributes, problems, err := validateProductAttributes(product.CategoryID, input.Attributes)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not load category attributes"})
+			return
+		}
+		if len(problems) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attributes", "details": problems})
+			return
+		}
+		updates.Attributes = attributes
+	}
+
+	// A rename moves the product to a slug derived from the new name unless
+	// the admin picked one; the old slug keeps redirecting.
+	slug := product.Slug
+	if input.Slug != "" || (input.ProductName != "" && input.ProductName != product.ProductName) {
+		var err error
+		if slug, err = helper.PickSlug(db.Db, helper.SlugProduct, input.Slug, input.ProductName, product.ProductID); err != nil {
+			slugError(c, err)
+			return
+		}
+	}
+
+	oldPrice := product.Price
+	oldSlug := product.Slug
+	if err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&product).Updates(updates).Error; err != nil {
+			return err
+		}
+		if input.BrandID != nil {
+			if err := tx.Model(&product).Update("brand_id", brandID).Error; err != nil {
+				return err
+			}
+		}
+		if err := helper.ChangeSlug(tx, helper.SlugProduct, product.ProductID, oldSlug, slug); err != nil {
+			return err
+		}
+		return helper.RecordPrice(tx, product.ProductID, middleware.CurrentID(c), helper.PriceSourceManual)
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Product updated successfully", "product_name": product.ProductName})
+	if input.Price > 0 && input.Price < oldPrice {
+		helper.NotifyWishlist(product.ProductID, notify.EventPriceDrop, gin.H{
+			"ProductID":   product.ProductID,
+			"ProductName": product.ProductName,
+			"OldPrice":    oldPrice,
+			"NewPrice":    input.Price,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product updated successfully", "product_name": product.ProductName, "slug": slug})
+}
+
+// UpdateProductLifecycle moves a product between draft, scheduled, active and
+// archived. Scheduled products go live once publish_at has passed.
+func UpdateProductLifecycle(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var input struct {
+		Lifecycle string     `json:"lifecycle" binding:"required"`
+		PublishAt *time.Time `json:"publish_at"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	publishAt, err := helper.CheckLifecycle(input.Lifecycle, input.PublishAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := db.Db.Model(&product).Updates(map[string]any{
+		"lifecycle":  input.Lifecycle,
+		"publish_at": publishAt,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update lifecycle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lifecycle updated", "lifecycle": input.Lifecycle, "publish_at": publishAt})
 }
 
 func DeleteProduct(c *gin.Context) {
@@ -127,7 +274,8 @@ func UpdateProductStock(c *gin.Context) {
 	}
 
 	var input struct {
-		Quantity int `json:"quantity" binding:"required"`
+		VariantID int `json:"variant_id"`
+		Quantity  int `json:"quantity" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -135,8 +283,64 @@ func UpdateProductStock(c *gin.Context) {
 		return
 	}
 
-	product.Quantity = input.Quantity
+	if input.VariantID != 0 {
+		if _, err := helper.FindVariant(db.Db, product.ProductID, input.VariantID); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+			return
+		}
+	}
+
+	previous, err := helper.SetStock(db.Db, product.ProductID, input.VariantID, input.Quantity, helper.StockChange{
+		Reason:  helper.StockAdminSet,
+		AdminID: middleware.CurrentID(c),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update stock"})
+		return
+	}
 
-	db.Db.Save(&product)
+	if previous <= 0 && input.Quantity > 0 {
+		helper.NotifyWishlist(product.ProductID, notify.EventBackInStock, gin.H{
+			"ProductID":   product.ProductID,
+			"ProductName": product.ProductName,
+		})
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Stock updated"})
 }
+
+func validateProductAttributes(categoryID uint, values map[string]any) (map[string]any, []string, error) {
+	categories, err := helper.LoadCategoryIndex()
+	if err != nil {
+		return nil, nil, err
+	}
+	schema, err := categories.AttributeSchema(db.Db, categoryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attributes, problems := helper.ValidateAttributes(schema, values)
+	return attributes, problems, nil
+}
+
+func slugError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, helper.ErrInvalidSlug):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, helper.ErrSlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not assign slug"})
+	}
+}
+
+// checkBrand treats a zero brand ID as no brand and reports whether a given
+// brand exists.
+func checkBrand(brandID *uint) (*uint, bool) {
+	if brandID == nil || *brandID == 0 {
+		return nil, true
+	}
+	var brand models.Brand
+	if err := db.Db.First(&brand, *brandID).Error; err != nil {
+		return nil, false
+	}
+	return brandID, true
+}
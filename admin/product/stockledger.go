@@ -0,0 +1,99 @@
+package product
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// StockMovements lists the stock ledger of a product, newest first. Pass
+// variant_id for a variant's ledger; reason, order_id, from and to
+// (YYYY-MM-DD) narrow it down. The response also compares the sum of all
+// movements with the quantity on record so discrepancies stand out.
+func StockMovements(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Unscoped().Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	variantID, err := strconv.Atoi(c.DefaultQuery("variant_id", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid variant_id"})
+		return
+	}
+
+	current := product.Quantity
+	if variantID != 0 {
+		variant, err := helper.FindVariant(db.Db.Unscoped(), product.ProductID, variantID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+			return
+		}
+		current = variant.Quantity
+	}
+
+	ledger := db.Db.Model(&models.StockMovement{}).Where("product_id = ? AND variant_id = ?", product.ProductID, variantID)
+
+	var balance int
+	if err := ledger.Session(&gorm.Session{}).Select("COALESCE(SUM(delta), 0)").Scan(&balance).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch stock movements"})
+		return
+	}
+
+	query := ledger.Session(&gorm.Session{})
+	if reason := c.Query("reason"); reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	if orderID := c.Query("order_id"); orderID != "" {
+		query = query.Where("order_id = ?", orderID)
+	}
+	for _, bound := range []struct{ param, condition string }{{"from", "created_at >= ?"}, {"to", "created_at < ?"}} {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		day, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + bound.param + ", use YYYY-MM-DD"})
+			return
+		}
+		if bound.param == "to" {
+			day = day.AddDate(0, 0, 1)
+		}
+		query = query.Where(bound.condition, day)
+	}
+
+	page, limit, offset := helper.Paginate(c)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch stock movements"})
+		return
+	}
+
+	var movements []models.StockMovement
+	if err := query.Order("created_at DESC, movement_id DESC").Limit(limit).Offset(offset).Find(&movements).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch stock movements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"product_id":       product.ProductID,
+		"variant_id":       variantID,
+		"current_quantity": current,
+		"ledger_quantity":  balance,
+		"in_sync":          balance == current,
+		"movements":        movements,
+		"page":             page,
+		"limit":            limit,
+		"total":            total,
+	})
+}
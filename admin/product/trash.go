@@ -0,0 +1,159 @@
+package product
+
+import (
+	"errors"
+	"net/http"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	errHasOrders = errors.New("product has order history")
+	errInBundle  = errors.New("product is part of a bundle")
+)
+
+// ListTrashedProducts shows soft-deleted products, most recently deleted first.
+func ListTrashedProducts(c *gin.Context) {
+	page, limit, offset := helper.Paginate(c)
+
+	query := db.Db.Unscoped().Model(&models.Product{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch trash"})
+		return
+	}
+
+	var products []models.Product
+	if err := query.Order("deleted_at DESC").Limit(limit).Offset(offset).Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"products": products,
+		"page":     page,
+		"limit":    limit,
+		"total":    total,
+	})
+}
+
+func RestoreProduct(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Unscoped().Where("product_id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found in trash"})
+		return
+	}
+
+	var category models.Category
+	if err := db.Db.First(&category, product.CategoryID).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "The product's category is deleted, restore the category first"})
+		return
+	}
+
+	if err := db.Db.Unscoped().Model(&product).Update("deleted_at", nil).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore product"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product restored successfully", "product_id": product.ProductID})
+}
+
+// PurgeProduct permanently removes a trashed product together with its
+// variants, images, offers, reviews, questions and cart and wishlist
+// entries. Products that appear on any order are kept so invoices and
+// returns stay intact.
+func PurgeProduct(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Unscoped().Where("product_id = ? AND deleted_at IS NOT NULL", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found in trash"})
+		return
+	}
+
+	var images []models.ProductImage
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		var orders int64
+		if err := tx.Model(&models.OrderItem{}).Where("product_id = ?", product.ProductID).Count(&orders).Error; err != nil {
+			return err
+		}
+		if orders > 0 {
+			return errHasOrders
+		}
+
+		var bundles int64
+		if err := tx.Model(&models.BundleComponent{}).
+			Joins("JOIN bundles ON bundles.bundle_id = bundle_components.bundle_id AND bundles.deleted_at IS NULL").
+			Where("bundle_components.product_id = ?", product.ProductID).Count(&bundles).Error; err != nil {
+			return err
+		}
+		if bundles > 0 {
+			return errInBundle
+		}
+
+		if err := tx.Where("product_id = ?", product.ProductID).Find(&images).Error; err != nil {
+			return err
+		}
+
+		questions := tx.Model(&models.ProductQuestion{}).Select("question_id").Where("product_id = ?", product.ProductID)
+		answers := tx.Model(&models.ProductAnswer{}).Select("answer_id").Where("question_id IN (?)", questions)
+		if err := tx.Where("answer_id IN (?)", answers).Delete(&models.AnswerVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id IN (?)", questions).Delete(&models.ProductAnswer{}).Error; err != nil {
+			return err
+		}
+
+		for _, model := range []any{
+			&models.ProductQuestion{},
+			&models.ProductImage{},
+			&models.ProductVariant{},
+			&models.Offer{},
+			&models.ReviewRating{},
+			&models.Cart{},
+			&models.Wishlist{},
+			&models.PriceHistory{},
+			&models.CompareItem{},
+		} {
+			if err := tx.Unscoped().Where("product_id = ?", product.ProductID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("entity_type = ? AND entity_id = ?", helper.SlugProduct, product.ProductID).
+			Delete(&models.SlugRedirect{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Notification{}).Where("product_id = ?", product.ProductID).
+			Update("product_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&product).Error
+	})
+	if errors.Is(err, errHasOrders) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Product has order history and cannot be purged"})
+		return
+	}
+	if errors.Is(err, errInBundle) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Product is part of a bundle, remove it from the bundle first"})
+		return
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"ProductID": product.ProductID,
+			"error":     err,
+		}).Error("Cannot purge product")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge product"})
+		return
+	}
+
+	for _, image := range images {
+		deleteRenditions(image.StorageKey)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product permanently deleted"})
+}
@@ -0,0 +1,178 @@
+package product
+
+import (
+	"net/http"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ListVariants(c *gin.Context) {
+	productID := c.Param("id")
+
+	var variants []models.ProductVariant
+	if err := db.Db.Where("product_id = ?", productID).Order("variant_id ASC").Find(&variants).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch variants"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"variants": variants})
+}
+
+func AddVariant(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var input models.VariantInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	variant := models.ProductVariant{
+		ProductID:  product.ProductID,
+		SKU:        strings.TrimSpace(input.SKU),
+		Color:      input.Color,
+		Fabric:     input.Fabric,
+		Size:       input.Size,
+		PriceDelta: input.PriceDelta,
+		Quantity:   input.Quantity,
+		Images:     input.Images,
+	}
+	if status, message := validateVariant(variant, product); message != "" {
+		c.JSON(status, gin.H{"message": message})
+		return
+	}
+
+	if err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&variant).Error; err != nil {
+			return err
+		}
+		if err := helper.RecordStock(tx, product.ProductID, variant.VariantID, variant.Quantity, helper.StockChange{
+			Reason:  helper.StockInitial,
+			AdminID: middleware.CurrentID(c),
+		}); err != nil {
+			return err
+		}
+		return helper.RecordPrice(tx, product.ProductID, middleware.CurrentID(c), helper.PriceSourceManual)
+	}); err != nil {
+		log.WithFields(log.Fields{
+			"ProductID": product.ProductID,
+			"SKU":       variant.SKU,
+			"error":     err,
+		}).Error("Cannot create variant")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not create variant"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Variant added successfully", "variant": variant})
+}
+
+func UpdateVariant(c *gin.Context) {
+	var variant models.ProductVariant
+	if err := db.Db.Where("variant_id = ?", c.Param("id")).First(&variant).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+
+	var product models.Product
+	if err := db.Db.First(&product, variant.ProductID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var input models.VariantInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if sku := strings.TrimSpace(input.SKU); sku != "" {
+		variant.SKU = sku
+	}
+	variant.Color = input.Color
+	variant.Fabric = input.Fabric
+	variant.Size = input.Size
+	variant.PriceDelta = input.PriceDelta
+	if input.Images != nil {
+		variant.Images = input.Images
+	}
+	if status, message := validateVariant(variant, product); message != "" {
+		c.JSON(status, gin.H{"message": message})
+		return
+	}
+
+	// Stock is changed through the stock endpoint, not here.
+	if err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&variant).Select("sku", "color", "fabric", "size", "price_delta", "images").Updates(&variant).Error; err != nil {
+			return err
+		}
+		return helper.RecordPrice(tx, product.ProductID, middleware.CurrentID(c), helper.PriceSourceManual)
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update variant"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Variant updated successfully", "variant": variant})
+}
+
+func DeleteVariant(c *gin.Context) {
+	var variant models.ProductVariant
+	if err := db.Db.Where("variant_id = ?", c.Param("id")).First(&variant).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+
+	if err := db.Db.Delete(&variant).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete variant"})
+		return
+	}
+	db.Db.Where("product_id = ? AND variant_id = ?", variant.ProductID, variant.VariantID).Delete(&models.Cart{})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Variant deleted successfully"})
+}
+
+// validateVariant returns the status and a user-facing message when the
+// variant is invalid, or an empty message when it can be saved.
+func validateVariant(variant models.ProductVariant, product models.Product) (int, string) {
+	if variant.SKU == "" {
+		return http.StatusBadRequest, "SKU is required"
+	}
+	if variant.Color == "" && variant.Fabric == "" && variant.Size == "" {
+		return http.StatusBadRequest, "At least one of color, fabric or size is required"
+	}
+	if variant.Quantity < 0 {
+		return http.StatusBadRequest, "Quantity cannot be negative"
+	}
+	if product.Price+variant.PriceDelta < 0 {
+		return http.StatusBadRequest, "Variant price cannot be negative"
+	}
+
+	// Deleted variants keep their SKU in the unique index.
+	var count int64
+	db.Db.Unscoped().Model(&models.ProductVariant{}).
+		Where("sku = ? AND variant_id <> ?", variant.SKU, variant.VariantID).
+		Count(&count)
+	if count != 0 {
+		return http.StatusConflict, "SKU already exists"
+	}
+
+	db.Db.Model(&models.ProductVariant{}).
+		Where("product_id = ? AND color = ? AND fabric = ? AND size = ? AND variant_id <> ?",
+			variant.ProductID, variant.Color, variant.Fabric, variant.Size, variant.VariantID).
+		Count(&count)
+	if count != 0 {
+		return http.StatusConflict, "A variant with these options already exists"
+	}
+	return http.StatusOK, ""
+}
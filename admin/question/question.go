@@ -0,0 +1,125 @@
+package question
+
+import (
+	"net/http"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListQuestions is the moderation queue: every question, newest first,
+// including hidden ones. ?product_id= and ?status= narrow it down.
+func ListQuestions(c *gin.Context) {
+	page, limit, offset := helper.Paginate(c)
+
+	query := db.Db.Model(&models.ProductQuestion{})
+	if productID := c.Query("product_id"); productID != "" {
+		query = query.Where("product_id = ?", productID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if c.Query("unanswered") == "true" {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM product_answers a WHERE a.question_id = product_questions.question_id AND a.status = ?)", helper.ModerationVisible)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch questions"})
+		return
+	}
+
+	var questions []models.ProductQuestion
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch questions"})
+		return
+	}
+
+	response, err := helper.QuestionResponses(questions, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch answers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"questions": response,
+		"page":      page,
+		"limit":     limit,
+		"total":     total,
+	})
+}
+
+func AnswerQuestion(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	adminID := claims.ID
+
+	var question models.ProductQuestion
+	if err := db.Db.Where("question_id = ?", c.Param("id")).First(&question).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+
+	var input models.AnswerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	answer := models.ProductAnswer{
+		QuestionID: question.QuestionID,
+		AdminID:    &adminID,
+		Body:       strings.TrimSpace(input.Body),
+		Status:     helper.ModerationVisible,
+	}
+	if err := db.Db.Create(&answer).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not post answer"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Answer posted", "answer_id": answer.AnswerID})
+}
+
+func ModerateQuestion(c *gin.Context) {
+	var input models.ModerationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := db.Db.Model(&models.ProductQuestion{}).Where("question_id = ?", c.Param("id")).Update("status", input.Status)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update question"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Question updated", "status": input.Status})
+}
+
+func ModerateAnswer(c *gin.Context) {
+	var input models.ModerationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := db.Db.Model(&models.ProductAnswer{}).Where("answer_id = ?", c.Param("id")).Update("status", input.Status)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update answer"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Answer not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Answer updated", "status": input.Status})
+}
@@ -337,6 +337,35 @@ func GetTopSellingCategories(c *gin.Context) {
 	c.JSON(http.StatusOK, categories)
 }
 
+func GetTopSellingBrands(c *gin.Context) {
+	limitParam := c.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	var brands []map[string]interface{}
+
+	err = db.Db.Table("order_items").
+		Joins("JOIN products ON order_items.product_id = products.product_id").
+		Joins("JOIN brands ON products.brand_id = brands.brand_id").
+		Select("brands.brand_id, brands.name, SUM(order_items.quantity) as total_sold").
+		Group("brands.brand_id, brands.name").
+		Order("total_sold DESC").
+		Limit(limit).
+		Scan(&brands).Error
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("Error in querying order_items")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, brands)
+}
+
 func GetLedgerBook(c *gin.Context) {
 	startDate := c.Query("start_date")
 	endDate := c.Query("end_date")
@@ -0,0 +1,141 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AttributeNumber  = "number"
+	AttributeEnum    = "enum"
+	AttributeBoolean = "boolean"
+)
+
+// AttributeSchema returns the attributes products of categoryID carry: those
+// defined on the category itself and on each of its ancestors. A definition
+// on a subcategory overrides an ancestor's attribute of the same name.
+func (index CategoryIndex) AttributeSchema(tx *gorm.DB, categoryID uint) ([]models.CategoryAttribute, error) {
+	path := index.Breadcrumbs(categoryID)
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	depth := make(map[uint]int, len(path))
+	ids := make([]uint, len(path))
+	for i, crumb := range path {
+		depth[crumb.CategoryID] = i
+		ids[i] = crumb.CategoryID
+	}
+
+	var attributes []models.CategoryAttribute
+	if err := tx.Where("category_id IN ?", ids).Order("attribute_id ASC").Find(&attributes).Error; err != nil {
+		return nil, err
+	}
+	sort.SliceStable(attributes, func(i, j int) bool {
+		return depth[attributes[i].CategoryID] < depth[attributes[j].CategoryID]
+	})
+
+	byName := map[string]int{}
+	var schema []models.CategoryAttribute
+	for _, attribute := range attributes {
+		if i, ok := byName[attribute.Name]; ok {
+			schema[i] = attribute
+			continue
+		}
+		byName[attribute.Name] = len(schema)
+		schema = append(schema, attribute)
+	}
+	return schema, nil
+}
+
+// ValidateAttributes checks values against schema and returns them with enum
+// values normalised to their canonical spelling, plus one message per problem.
+func ValidateAttributes(schema []models.CategoryAttribute, values map[string]any) (map[string]any, []string) {
+	var problems []string
+	clean := map[string]any{}
+
+	known := map[string]models.CategoryAttribute{}
+	for _, attribute := range schema {
+		known[attribute.Name] = attribute
+	}
+	for name := range values {
+		if _, ok := known[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s is not an attribute of this category", name))
+		}
+	}
+
+	for _, attribute := range schema {
+		value, ok := values[attribute.Name]
+		if !ok || value == nil {
+			if attribute.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", attribute.Name))
+			}
+			continue
+		}
+
+		switch attribute.Type {
+		case AttributeNumber:
+			number, ok := value.(float64)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s must be a number", attribute.Name))
+				continue
+			}
+			clean[attribute.Name] = number
+		case AttributeBoolean:
+			flag, ok := value.(bool)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s must be true or false", attribute.Name))
+				continue
+			}
+			clean[attribute.Name] = flag
+		case AttributeEnum:
+			text, _ := value.(string)
+			option, ok := matchOption(attribute.Options, text)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s must be one of %s", attribute.Name, strings.Join(attribute.Options, ", ")))
+				continue
+			}
+			clean[attribute.Name] = option
+		}
+	}
+
+	sort.Strings(problems)
+	return clean, problems
+}
+
+// AttributeValues pairs stored values with their labels and units for display.
+func AttributeValues(schema []models.CategoryAttribute, values map[string]any) []responsemodels.Attribute {
+	var attributes []responsemodels.Attribute
+	for _, attribute := range schema {
+		value, ok := values[attribute.Name]
+		if !ok {
+			continue
+		}
+		label := attribute.Label
+		if label == "" {
+			label = attribute.Name
+		}
+		attributes = append(attributes, responsemodels.Attribute{
+			Name:  attribute.Name,
+			Label: label,
+			Value: value,
+			Unit:  attribute.Unit,
+		})
+	}
+	return attributes
+}
+
+func matchOption(options []string, value string) (string, bool) {
+	for _, option := range options {
+		if strings.EqualFold(option, strings.TrimSpace(value)) {
+			return option, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,93 @@
+package helper
+
+import (
+	"math"
+
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"gorm.io/gorm"
+)
+
+// BundleDetails resolves the components of bundle against current products
+// and returns the response together with the price each component carries
+// when one bundle is sold. The bundle price is split across components in
+// proportion to their list prices, rounded to whole rupees, with the
+// rounding difference on the last component. Unpublished components make
+// the bundle unavailable (stock 0).
+func BundleDetails(tx *gorm.DB, bundle models.Bundle) (responsemodels.Bundle, []int, error) {
+	response := responsemodels.Bundle{
+		BundleID:    bundle.BundleID,
+		Name:        bundle.Name,
+		Description: bundle.Description,
+		Price:       bundle.Price,
+		ImgURL:      bundle.ImgURL,
+		Stock:       math.MaxInt,
+	}
+
+	listTotals := make([]float64, len(bundle.Components))
+	for i, component := range bundle.Components {
+		var product models.Product
+		if err := tx.Unscoped().First(&product, component.ProductID).Error; err != nil {
+			return response, nil, err
+		}
+
+		available := product.Quantity
+		var variant *models.ProductVariant
+		item := responsemodels.BundleComponent{
+			ProductID:   product.ProductID,
+			VariantID:   component.VariantID,
+			ProductName: product.ProductName,
+			Quantity:    component.Quantity,
+		}
+		if component.VariantID != 0 {
+			v, err := FindVariant(tx.Unscoped(), product.ProductID, component.VariantID)
+			if err != nil {
+				return response, nil, err
+			}
+			variant = &v
+			available = v.Quantity
+			item.SKU = v.SKU
+		}
+		item.UnitPrice = UnitPrice(product, variant)
+
+		if product.DeletedAt.Valid || product.Lifecycle != LifecycleActive || (variant != nil && variant.DeletedAt.Valid) {
+			available = 0
+		}
+		response.Stock = min(response.Stock, available/component.Quantity)
+
+		listTotals[i] = item.UnitPrice * float64(component.Quantity)
+		response.ListPrice += listTotals[i]
+		response.Components = append(response.Components, item)
+	}
+	if len(bundle.Components) == 0 {
+		response.Stock = 0
+	}
+
+	return response, allocate(bundle.Price, listTotals), nil
+}
+
+func allocate(price float64, weights []float64) []int {
+	shares := make([]int, len(weights))
+	if len(weights) == 0 {
+		return shares
+	}
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+
+	target := int(math.Round(price))
+	remaining := target
+	for i, weight := range weights[:len(weights)-1] {
+		share := target / len(weights)
+		if total > 0 {
+			share = int(math.Round(price * weight / total))
+		}
+		shares[i] = share
+		remaining -= share
+	}
+	shares[len(shares)-1] = remaining
+	return shares
+}
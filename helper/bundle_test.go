@@ -0,0 +1,40 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		price   float64
+		weights []float64
+		want    []int
+	}{
+		{"proportional", 1500, []float64{1000, 500}, []int{1000, 500}},
+		{"remainder on last", 100, []float64{1, 1, 1}, []int{33, 33, 34}},
+		{"rounded shares", 1000, []float64{333, 333, 334}, []int{333, 333, 334}},
+		{"price rounded to rupees", 99.6, []float64{1, 1}, []int{50, 50}},
+		{"zero weights split evenly", 100, []float64{0, 0, 0}, []int{33, 33, 34}},
+		{"single component", 749, []float64{1200}, []int{749}},
+		{"no components", 500, nil, []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocate(tt.price, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("allocate(%v, %v) = %v, want %v", tt.price, tt.weights, got, tt.want)
+			}
+
+			sum := 0
+			for _, share := range got {
+				sum += share
+			}
+			if len(got) > 0 && sum != int(tt.price+0.5) {
+				t.Errorf("shares add up to %d, want %d", sum, int(tt.price+0.5))
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package helper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	db "admin/DB"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"gorm.io/gorm"
+)
+
+// CategoryIndex holds every live category keyed by ID so paths and subtrees
+// can be worked out without a query per level.
+type CategoryIndex map[uint]models.Category
+
+func LoadCategoryIndex() (CategoryIndex, error) {
+	var categories []models.Category
+	if err := db.Db.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	index := make(CategoryIndex, len(categories))
+	for _, category := range categories {
+		index[category.CategoryID] = category
+	}
+	return index, nil
+}
+
+// Breadcrumbs returns the path from the root category down to categoryID.
+func (index CategoryIndex) Breadcrumbs(categoryID uint) []models.Breadcrumb {
+	var path []models.Breadcrumb
+	seen := map[uint]bool{}
+
+	for id := categoryID; id != 0 && !seen[id]; {
+		category, ok := index[id]
+		if !ok {
+			break
+		}
+		seen[id] = true
+		path = append([]models.Breadcrumb{{CategoryID: category.CategoryID, Name: category.CategoryName, Slug: category.Slug}}, path...)
+
+		id = 0
+		if category.ParentID != nil {
+			id = *category.ParentID
+		}
+	}
+	return path
+}
+
+// PathSeparator joins category names in a path such as "Living Room > Sofas".
+const PathSeparator = " > "
+
+// Path returns the full category path of categoryID.
+func (index CategoryIndex) Path(categoryID uint) string {
+	var names []string
+	for _, crumb := range index.Breadcrumbs(categoryID) {
+		names = append(names, crumb.Name)
+	}
+	return strings.Join(names, PathSeparator)
+}
+
+// Resolve finds a category by its full path, or by its bare name when that
+// name is unique. Matching ignores case.
+func (index CategoryIndex) Resolve(nameOrPath string) (uint, error) {
+	want := strings.ToLower(strings.TrimSpace(nameOrPath))
+	if want == "" {
+		return 0, fmt.Errorf("category is required")
+	}
+
+	var matches []uint
+	for id, category := range index {
+		if strings.ToLower(index.Path(id)) == want {
+			return id, nil
+		}
+		if strings.ToLower(category.CategoryName) == want {
+			matches = append(matches, id)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("category %q not found", nameOrPath)
+	case 1:
+		return matches[0], nil
+	default:
+		return 0, fmt.Errorf("category %q is ambiguous, use the full path", nameOrPath)
+	}
+}
+
+// Tree builds the nested category tree, siblings sorted by name.
+func (index CategoryIndex) Tree() []responsemodels.CategoryNode {
+	children := map[uint][]models.Category{}
+	for _, category := range index {
+		var parent uint
+		if category.ParentID != nil {
+			if _, ok := index[*category.ParentID]; ok {
+				parent = *category.ParentID
+			}
+		}
+		children[parent] = append(children[parent], category)
+	}
+
+	var build func(parent uint) []responsemodels.CategoryNode
+	build = func(parent uint) []responsemodels.CategoryNode {
+		list := children[parent]
+		sort.Slice(list, func(i, j int) bool { return list[i].CategoryName < list[j].CategoryName })
+
+		nodes := make([]responsemodels.CategoryNode, len(list))
+		for i, category := range list {
+			nodes[i] = responsemodels.CategoryNode{
+				CategoryID: category.CategoryID,
+				Name:       category.CategoryName,
+				Slug:       category.Slug,
+				Children:   build(category.CategoryID),
+			}
+		}
+		return nodes
+	}
+	return build(0)
+}
+
+// DescendantIDs returns categoryID and the IDs of every live category below it.
+func DescendantIDs(tx *gorm.DB, categoryID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Raw(`
+		WITH RECURSIVE tree AS (
+			SELECT category_id FROM categories WHERE category_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT c.category_id FROM categories c
+			JOIN tree t ON c.parent_id = t.category_id
+			WHERE c.deleted_at IS NULL
+		)
+		SELECT category_id FROM tree
+	`, categoryID).Scan(&ids).Error
+	return ids, err
+}
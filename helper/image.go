@@ -0,0 +1,94 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"net/http"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// ImageSizes is the longest edge, in pixels, of each stored rendition.
+var ImageSizes = map[string]int{
+	"thumbnail": 150,
+	"medium":    600,
+	"large":     1200,
+}
+
+// maxImagePixels caps the decoded size so a small, highly compressed file
+// cannot claim gigabytes of memory.
+const maxImagePixels = 40_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image type, use JPEG, PNG, GIF or WebP")
+	ErrImageTooLarge    = fmt.Errorf("image is larger than %d megapixels", maxImagePixels/1_000_000)
+)
+
+// ResizeImage decodes data and returns a JPEG for every entry in ImageSizes.
+// Images are scaled down to fit, never up.
+func ResizeImage(data []byte) (map[string][]byte, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode image: %w", err)
+	}
+
+	renditions := make(map[string][]byte, len(ImageSizes))
+	for name, size := range ImageSizes {
+		resized := fitWithin(src, size)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("cannot encode %s image: %w", name, err)
+		}
+		renditions[name] = buf.Bytes()
+	}
+	return renditions, nil
+}
+
+func fitWithin(src image.Image, maxEdge int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width > maxEdge || height > maxEdge {
+		if width >= height {
+			height = height * maxEdge / width
+			width = maxEdge
+		} else {
+			width = width * maxEdge / height
+			height = maxEdge
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	// Draw onto white so transparent PNGs do not turn black as JPEGs.
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
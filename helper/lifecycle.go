@@ -0,0 +1,58 @@
+package helper
+
+import (
+	"errors"
+	"time"
+
+	db "admin/DB"
+	"admin/models"
+
+	"gorm.io/gorm"
+)
+
+// Product lifecycle states. Only active products are shown to shoppers;
+// whether they can be bought right now is a separate matter of stock.
+const (
+	LifecycleDraft     = "draft"
+	LifecycleScheduled = "scheduled"
+	LifecycleActive    = "active"
+	LifecycleArchived  = "archived"
+)
+
+var (
+	ErrInvalidLifecycle = errors.New("lifecycle must be draft, scheduled, active or archived")
+	ErrPublishAtMissing = errors.New("scheduled products need a publish_at in the future")
+)
+
+// Published is a gorm scope limiting a products query to storefront-visible rows.
+func Published(tx *gorm.DB) *gorm.DB {
+	return tx.Where("products.lifecycle = ?", LifecycleActive)
+}
+
+// CheckLifecycle validates a lifecycle change and returns the publish time to
+// store with it: the requested time for scheduled products, now for products
+// going live and nil otherwise.
+func CheckLifecycle(lifecycle string, publishAt *time.Time) (*time.Time, error) {
+	now := time.Now()
+	switch lifecycle {
+	case LifecycleDraft, LifecycleArchived:
+		return nil, nil
+	case LifecycleActive:
+		return &now, nil
+	case LifecycleScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return nil, ErrPublishAtMissing
+		}
+		return publishAt, nil
+	default:
+		return nil, ErrInvalidLifecycle
+	}
+}
+
+// PublishScheduledProducts activates scheduled products whose publish time
+// has passed.
+func PublishScheduledProducts() error {
+	return db.Db.Model(&models.Product{}).
+		Where("lifecycle = ? AND publish_at <= ?", LifecycleScheduled, time.Now()).
+		Update("lifecycle", LifecycleActive).Error
+}
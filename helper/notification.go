@@ -0,0 +1,116 @@
+package helper
+
+import (
+	db "admin/DB"
+	"admin/models"
+	"admin/notify"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// NotifyUser sends the templated e-mail for event to the user and, for events
+// that appear in the inbox, records an in-app notification. The user's name is
+// added to data as "UserName".
+func NotifyUser(userID uint, event notify.Event, data gin.H, attachments ...notify.Attachment) {
+	var user models.User
+	if err := db.Db.Select("id, user_name, email").First(&user, userID).Error; err != nil {
+		log.WithFields(log.Fields{
+			"UserID": userID,
+			"event":  event,
+			"error":  err,
+		}).Error("cannot find user to notify")
+		return
+	}
+
+	notifyLoaded(user, event, data, attachments...)
+}
+
+func notifyLoaded(user models.User, event notify.Event, data gin.H, attachments ...notify.Attachment) {
+	if data == nil {
+		data = gin.H{}
+	}
+	data["UserName"] = user.UserName
+
+	addToInbox(user.ID, event, data)
+	notify.Email(user.Email, event, data, attachments...)
+}
+
+// NotifyWishlist notifies every user who has productID in their wishlist. It
+// returns at once; the users are looked up and notified in the background and
+// failures are logged.
+func NotifyWishlist(productID int, event notify.Event, data gin.H) {
+	shared := gin.H{}
+	for k, v := range data {
+		shared[k] = v
+	}
+	go notifyWishlist(productID, event, shared)
+}
+
+func notifyWishlist(productID int, event notify.Event, data gin.H) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{
+				"ProductID": productID,
+				"event":     event,
+				"panic":     r,
+			}).Error("wishlist notification panicked")
+		}
+	}()
+
+	var users []models.User
+	if err := db.Db.Select("id, user_name, email").
+		Where("id IN (?)", db.Db.Model(&models.Wishlist{}).Select("user_id").Where("product_id = ?", productID)).
+		Find(&users).Error; err != nil {
+		log.WithFields(log.Fields{
+			"ProductID": productID,
+			"event":     event,
+			"error":     err,
+		}).Error("cannot find wishlist users to notify")
+		return
+	}
+
+	for _, user := range users {
+		userData := gin.H{}
+		for k, v := range data {
+			userData[k] = v
+		}
+		notifyLoaded(user, event, userData)
+	}
+}
+
+func addToInbox(userID uint, event notify.Event, data gin.H) {
+	title, body, ok, err := notify.RenderInbox(event, data)
+	if !ok {
+		return
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"UserID": userID,
+			"event":  event,
+			"error":  err,
+		}).Error("cannot render inbox notification")
+		return
+	}
+
+	notification := models.Notification{
+		UserID: userID,
+		Type:   string(event),
+		Title:  title,
+		Body:   body,
+	}
+	if orderID, ok := data["OrderID"].(int); ok {
+		notification.OrderID = orderID
+	}
+	if productID, ok := data["ProductID"].(int); ok {
+		notification.ProductID = productID
+	}
+
+	if err := db.Db.Create(&notification).Error; err != nil {
+		log.WithFields(log.Fields{
+			"UserID": userID,
+			"event":  event,
+			"error":  err,
+		}).Error("cannot save inbox notification")
+	}
+}
@@ -2,11 +2,36 @@ package helper
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
-	"log"
 	"math/big"
-	"net/smtp"
-	"os"
+	"time"
+
+	db "admin/DB"
+	"admin/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	OTPPurposeSignup = "signup"
+	OTPPurposeLogin  = "login"
+)
+
+const (
+	OTPExpiry         = 5 * time.Minute
+	OTPMaxAttempts    = 5
+	OTPResendCooldown = time.Minute
+	OTPDailyLimit     = 10
+)
+
+var (
+	ErrOTPInvalid         = errors.New("invalid OTP")
+	ErrOTPExpired         = errors.New("OTP has expired")
+	ErrOTPTooManyAttempts = errors.New("too many incorrect attempts, please request a new OTP")
+	ErrOTPCooldown        = errors.New("please wait before requesting another OTP")
+	ErrOTPDailyLimit      = errors.New("daily OTP limit reached, please try again tomorrow")
 )
 
 func GenerateOTP() (string, error) {
@@ -14,30 +39,105 @@ func GenerateOTP() (string, error) {
 	for i := 0; i < 6; i++ {
 		digit, err := rand.Int(rand.Reader, big.NewInt(10))
 		if err != nil {
-			log.Fatal("Error Generating OTP", err)
+			return "", fmt.Errorf("error generating otp: %w", err)
 		}
 		otp += fmt.Sprintf("%d", digit)
 	}
 	return otp, nil
 }
 
-func SendEmail(to, otp string) error {
-	from := os.Getenv("EMAIL_ADDRESS")
-	password := os.Getenv("EMAIL_PASSWORD")
+// IssueOTP creates or replaces the OTP for recipient and purpose and returns
+// the plaintext code so the caller can deliver it. Only a bcrypt hash of the
+// code is stored. The resend cooldown and daily cap are enforced here.
+func IssueOTP(recipient, purpose string) (string, error) {
+	now := time.Now()
 
-	if from == "" || password == "" {
-		log.Fatal("Email credentials not set in environment variable")
+	var otp models.OTP
+	err := db.Db.Where("recipient = ? AND purpose = ?", recipient, purpose).First(&otp).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
 	}
-	fmt.Println(otp)
-
-	msg := "Your OTP for Signup is " + otp
 
-	err := smtp.SendMail("smtp.gmail.com:587", smtp.PlainAuth("", from, password, "smtp.gmail.com"),
-		from, []string{to}, []byte(msg))
+	if otp.ID != 0 {
+		if now.Sub(otp.LastSentAt) < OTPResendCooldown {
+			return "", ErrOTPCooldown
+		}
+		if now.Sub(otp.WindowStart) >= 24*time.Hour {
+			otp.SendCount = 0
+			otp.WindowStart = now
+		}
+		if otp.SendCount >= OTPDailyLimit {
+			return "", ErrOTPDailyLimit
+		}
+	} else {
+		otp.Recipient = recipient
+		otp.Purpose = purpose
+		otp.WindowStart = now
+	}
 
+	code, err := GenerateOTP()
 	if err != nil {
-		log.Fatal("Error Sending otp", err)
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("cannot hash otp: %w", err)
+	}
+
+	otp.CodeHash = string(hash)
+	otp.Attempts = 0
+	otp.Expiry = now.Add(OTPExpiry)
+	otp.SendCount++
+	otp.LastSentAt = now
+
+	if err := db.Db.Save(&otp).Error; err != nil {
+		return "", fmt.Errorf("cannot save otp: %w", err)
+	}
+	return code, nil
+}
+
+// CheckOTP verifies code against the stored OTP for recipient and purpose.
+// Every check counts as an attempt; the OTP is consumed on success.
+func CheckOTP(recipient, purpose, code string) error {
+	var otp models.OTP
+	if err := db.Db.Where("recipient = ? AND purpose = ?", recipient, purpose).First(&otp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOTPInvalid
+		}
+		return err
+	}
+
+	if time.Now().After(otp.Expiry) {
+		return ErrOTPExpired
+	}
+
+	result := db.Db.Model(&models.OTP{}).
+		Where("id = ? AND attempts < ?", otp.ID, OTPMaxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOTPTooManyAttempts
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(otp.CodeHash), []byte(code)); err != nil {
+		return ErrOTPInvalid
+	}
+
+	return db.Db.Delete(&otp).Error
+}
+
+// PurgeStaleOTPs removes OTPs that have expired and are outside their daily
+// window, and signups that were never verified within a day.
+func PurgeStaleOTPs() error {
+	cutoff := time.Now().Add(-24 * time.Hour)
+
+	if err := db.Db.Where("expiry < ? AND window_start < ?", time.Now(), cutoff).Delete(&models.OTP{}).Error; err != nil {
+		return fmt.Errorf("cannot purge otps: %w", err)
+	}
+	if err := db.Db.Where("created_at < ? OR created_at IS NULL", cutoff).Delete(&models.TempUser{}).Error; err != nil {
+		return fmt.Errorf("cannot purge temp users: %w", err)
 	}
-	fmt.Println("OTP sent successfully :", otp)
 	return nil
 }
@@ -0,0 +1,31 @@
+package helper
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Paginate reads the page and limit query parameters, falling back to the
+// first page and DefaultPageSize when they are missing or invalid.
+func Paginate(c *gin.Context) (page, limit, offset int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err = strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	return page, limit, (page - 1) * limit
+}
@@ -0,0 +1,119 @@
+package helper
+
+import (
+	"time"
+
+	db "admin/DB"
+	"admin/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PriceSourceBaseline = "baseline"
+	PriceSourceManual   = "manual"
+	PriceSourceImport   = "import"
+	PriceSourceOffer    = "offer"
+
+	LowestPriceWindow = 30 * 24 * time.Hour
+)
+
+// RecordPrice logs the current price of the product and of each of its
+// variants whose price differs from the last logged one. Call it in the
+// transaction that changed the price or the offer.
+func RecordPrice(tx *gorm.DB, productID int, adminID uint, source string) error {
+	var product models.Product
+	if err := tx.First(&product, productID).Error; err != nil {
+		return err
+	}
+	var variants []models.ProductVariant
+	if err := tx.Where("product_id = ?", productID).Find(&variants).Error; err != nil {
+		return err
+	}
+
+	var admin *uint
+	if adminID != 0 {
+		admin = &adminID
+	}
+	now := time.Now()
+
+	entries := []models.PriceHistory{{ProductID: productID, Price: UnitPrice(product, nil)}}
+	for i := range variants {
+		entries = append(entries, models.PriceHistory{
+			ProductID: productID,
+			VariantID: variants[i].VariantID,
+			Price:     UnitPrice(product, &variants[i]),
+		})
+	}
+
+	for _, entry := range entries {
+		entry.OfferPercentage = OfferPercentage(tx, productID, entry.VariantID)
+		entry.FinalPrice = entry.Price * float64(100-entry.OfferPercentage) / 100
+
+		var last models.PriceHistory
+		err := tx.Where("product_id = ? AND variant_id = ?", productID, entry.VariantID).
+			Order("changed_at DESC, price_history_id DESC").First(&last).Error
+		if err == nil && last.Price == entry.Price && last.OfferPercentage == entry.OfferPercentage {
+			continue
+		}
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		entry.AdminID = admin
+		entry.Source = source
+		entry.ChangedAt = now
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LowestPrices returns the lowest final price each product had over the last
+// 30 days, counting the price that was already in effect when the window
+// opened. Products without any history are left out.
+func LowestPrices(productIDs []int) (map[int]float64, error) {
+	var rows []struct {
+		ProductID int
+		Lowest    float64
+	}
+	since := time.Now().Add(-LowestPriceWindow)
+	err := db.Db.Raw(`
+		SELECT h.product_id, MIN(h.final_price) AS lowest
+		FROM price_histories h
+		WHERE h.variant_id = 0 AND h.product_id IN ?
+		AND (h.changed_at >= ? OR h.price_history_id = (
+			SELECT p.price_history_id FROM price_histories p
+			WHERE p.product_id = h.product_id AND p.variant_id = 0 AND p.changed_at < ?
+			ORDER BY p.changed_at DESC, p.price_history_id DESC LIMIT 1
+		))
+		GROUP BY h.product_id
+	`, productIDs, since, since).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	lowest := make(map[int]float64, len(rows))
+	for _, row := range rows {
+		lowest[row.ProductID] = row.Lowest
+	}
+	return lowest, nil
+}
+
+// BackfillPriceHistory gives products that have no price history yet a
+// baseline entry, so the first real change has something to compare with.
+func BackfillPriceHistory() error {
+	var ids []int
+	if err := db.Db.Model(&models.Product{}).
+		Where("NOT EXISTS (SELECT 1 FROM price_histories h WHERE h.product_id = products.product_id)").
+		Pluck("product_id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := RecordPrice(db.Db, id, 0, PriceSourceBaseline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
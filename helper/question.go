@@ -0,0 +1,82 @@
+package helper
+
+import (
+	db "admin/DB"
+	"admin/models"
+	"admin/models/responsemodels"
+)
+
+const (
+	ModerationVisible = "visible"
+	ModerationHidden  = "hidden"
+)
+
+// IsVerifiedBuyer reports whether userID has received productID in a
+// delivered order.
+func IsVerifiedBuyer(userID uint, productID int) (bool, error) {
+	var count int64
+	err := db.Db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.order_id = order_items.order_id").
+		Where("order_items.user_id = ? AND order_items.product_id = ? AND orders.status = ?", userID, productID, "Delivered").
+		Count(&count).Error
+	return count > 0, err
+}
+
+// QuestionResponses attaches answers to questions, best voted first. Hidden
+// answers are left out unless includeHidden is set.
+func QuestionResponses(questions []models.ProductQuestion, includeHidden bool) ([]responsemodels.Question, error) {
+	response := make([]responsemodels.Question, len(questions))
+	if len(questions) == 0 {
+		return response, nil
+	}
+
+	ids := make([]uint, len(questions))
+	for i, question := range questions {
+		ids[i] = question.QuestionID
+	}
+
+	query := db.Db.Where("question_id IN ?", ids)
+	if !includeHidden {
+		query = query.Where("status = ?", ModerationVisible)
+	}
+	var answers []models.ProductAnswer
+	if err := query.Order("upvotes DESC, created_at ASC").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+
+	byQuestion := map[uint][]responsemodels.Answer{}
+	for _, answer := range answers {
+		answerer := "verified_buyer"
+		if answer.AdminID != nil {
+			answerer = "admin"
+		}
+		item := responsemodels.Answer{
+			AnswerID:  answer.AnswerID,
+			Body:      answer.Body,
+			Answerer:  answerer,
+			Upvotes:   answer.Upvotes,
+			CreatedAt: answer.CreatedAt,
+		}
+		if includeHidden {
+			item.Status = answer.Status
+		}
+		byQuestion[answer.QuestionID] = append(byQuestion[answer.QuestionID], item)
+	}
+
+	for i, question := range questions {
+		response[i] = responsemodels.Question{
+			QuestionID: question.QuestionID,
+			ProductID:  question.ProductID,
+			Body:       question.Body,
+			CreatedAt:  question.CreatedAt,
+			Answers:    byQuestion[question.QuestionID],
+		}
+		if includeHidden {
+			response[i].Status = question.Status
+		}
+		if response[i].Answers == nil {
+			response[i].Answers = []responsemodels.Answer{}
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,105 @@
+package helper
+
+import (
+	"sync"
+	"time"
+
+	db "admin/DB"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MaxRelated      = 12
+	relatedCacheTTL = 30 * time.Minute
+)
+
+type relatedEntry struct {
+	products []responsemodels.RelatedProduct
+	expires  time.Time
+}
+
+var relatedCache = struct {
+	sync.RWMutex
+	entries map[int]relatedEntry
+}{entries: map[int]relatedEntry{}}
+
+// RefreshProductAffinity rebuilds the co-purchase table from order history:
+// every pair of products bought in the same order scores one point per order.
+// Canceled and failed orders are ignored.
+func RefreshProductAffinity() error {
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_affinities").Error; err != nil {
+			return err
+		}
+		return tx.Exec(`
+			INSERT INTO product_affinities (product_id, related_id, score, updated_at)
+			SELECT a.product_id, b.product_id, COUNT(DISTINCT a.order_id), NOW()
+			FROM order_items a
+			JOIN order_items b ON b.order_id = a.order_id AND b.product_id <> a.product_id
+			JOIN orders o ON o.order_id = a.order_id
+			WHERE o.status NOT IN ('Canceled', 'Failed')
+			GROUP BY a.product_id, b.product_id
+		`).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	relatedCache.Lock()
+	relatedCache.entries = map[int]relatedEntry{}
+	relatedCache.Unlock()
+	return nil
+}
+
+// RelatedProducts returns up to MaxRelated published products for product:
+// those most often bought together with it first, then newer products from
+// the same category. Results are cached until the next affinity refresh or
+// for relatedCacheTTL, whichever comes first.
+func RelatedProducts(product models.Product) ([]responsemodels.RelatedProduct, error) {
+	relatedCache.RLock()
+	entry, ok := relatedCache.entries[product.ProductID]
+	relatedCache.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.products, nil
+	}
+
+	var related []responsemodels.RelatedProduct
+	columns := "products.product_id, products.product_name, products.price, products.img_url"
+
+	if err := db.Db.Model(&models.Product{}).Scopes(Published).
+		Select(columns+", 'bought_together' AS reason").
+		Joins("JOIN product_affinities a ON a.related_id = products.product_id").
+		Where("a.product_id = ?", product.ProductID).
+		Order("a.score DESC, products.product_id ASC").
+		Limit(MaxRelated).
+		Scan(&related).Error; err != nil {
+		return nil, err
+	}
+
+	if len(related) < MaxRelated {
+		exclude := []int{product.ProductID}
+		for _, r := range related {
+			exclude = append(exclude, r.ProductID)
+		}
+
+		var fallback []responsemodels.RelatedProduct
+		if err := db.Db.Model(&models.Product{}).Scopes(Published).
+			Select(columns+", 'same_category' AS reason").
+			Where("products.category_id = ? AND products.product_id NOT IN ?", product.CategoryID, exclude).
+			Order("products.created_at DESC").
+			Limit(MaxRelated - len(related)).
+			Scan(&fallback).Error; err != nil {
+			return nil, err
+		}
+		related = append(related, fallback...)
+	}
+
+	relatedCache.Lock()
+	relatedCache.entries[product.ProductID] = relatedEntry{products: related, expires: time.Now().Add(relatedCacheTTL)}
+	relatedCache.Unlock()
+
+	return related, nil
+}
@@ -0,0 +1,215 @@
+package helper
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	db "admin/DB"
+	"admin/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	ReservationHeld      = "held"
+	ReservationCommitted = "committed"
+	ReservationReleased  = "released"
+
+	// ReservationTTL is how long stock stays held while the buyer is away
+	// approving the payment.
+	ReservationTTL = 15 * time.Minute
+)
+
+var ErrReservationCommitted = errors.New("reservation already committed")
+
+// ReservationLine is a quantity of a product or variant to hold.
+type ReservationLine struct {
+	ProductID int
+	VariantID int
+	Quantity  int
+}
+
+// NewCheckoutID returns an identifier for a checkout that has no payment
+// reference of its own.
+func NewCheckoutID() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return "chk_" + hex.EncodeToString(buf)
+}
+
+// ReserveStock takes the stock for every line off the shelf and records it
+// as held for checkoutID until ttl passes. Either every line is reserved or,
+// when one is short, none is and the error wraps ErrInsufficientStock.
+func ReserveStock(checkoutID string, userID uint, lines []ReservationLine, ttl time.Duration) error {
+	merged := mergeLines(lines)
+	expires := time.Now().Add(ttl)
+	return db.Db.Transaction(func(tx *gorm.DB) error {
+		return reserveLines(merged, func(line ReservationLine) error {
+			if err := AdjustStock(tx, line.ProductID, line.VariantID, -line.Quantity,
+				StockChange{Reason: StockReserved, CheckoutID: checkoutID}); err != nil {
+				return err
+			}
+			return tx.Create(&models.StockReservation{
+				CheckoutID: checkoutID,
+				UserID:     userID,
+				ProductID:  line.ProductID,
+				VariantID:  line.VariantID,
+				Quantity:   line.Quantity,
+				Status:     ReservationHeld,
+				ExpiresAt:  expires,
+			}).Error
+		})
+	})
+}
+
+// mergeLines adds up the quantities of lines for the same product and
+// variant, keeping the order in which they first appear.
+func mergeLines(lines []ReservationLine) []ReservationLine {
+	type key struct{ product, variant int }
+	index := map[key]int{}
+	var merged []ReservationLine
+	for _, line := range lines {
+		k := key{line.ProductID, line.VariantID}
+		if i, ok := index[k]; ok {
+			merged[i].Quantity += line.Quantity
+			continue
+		}
+		index[k] = len(merged)
+		merged = append(merged, line)
+	}
+	return merged
+}
+
+// reserveLines takes each line in turn and stops at the first that fails,
+// so the caller's transaction can roll back the ones already taken.
+func reserveLines(lines []ReservationLine, take func(ReservationLine) error) error {
+	for _, line := range lines {
+		if err := take(line); err != nil {
+			if errors.Is(err, ErrInsufficientStock) {
+				return fmt.Errorf("product %d: %w", line.ProductID, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// CommitReservations turns the held stock of a checkout into stock sold by
+// orderID. The stock already left the shelf when it was reserved, so the
+// ledger gets a zero-delta sold movement tying it to the order. Call it in
+// the transaction that creates the order.
+func CommitReservations(tx *gorm.DB, checkoutID string, orderID int) error {
+	var reservations []models.StockReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("checkout_id = ? AND status = ?", checkoutID, ReservationHeld).
+		Find(&reservations).Error; err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		if err := recordMovement(tx, reservation.ProductID, reservation.VariantID, 0,
+			StockChange{Reason: StockSold, OrderID: orderID, CheckoutID: checkoutID}); err != nil {
+			return err
+		}
+		if err := tx.Model(&reservation).Updates(map[string]any{
+			"status":   ReservationCommitted,
+			"order_id": orderID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReleaseReservations puts the held stock of a checkout back on the shelf.
+// Committed reservations are left alone, so releasing after the order went
+// through does nothing.
+func ReleaseReservations(tx *gorm.DB, checkoutID string) error {
+	return tx.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.StockReservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("checkout_id = ? AND status = ?", checkoutID, ReservationHeld).
+			Find(&reservations).Error; err != nil {
+			return err
+		}
+		for _, reservation := range reservations {
+			if err := AdjustStock(tx, reservation.ProductID, reservation.VariantID, reservation.Quantity,
+				StockChange{Reason: StockReleased, CheckoutID: checkoutID}); err != nil {
+				return err
+			}
+			if err := tx.Model(&reservation).Update("status", ReservationReleased).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HoldReservations makes sure the stock of a checkout is held right before
+// payment is captured. Reservations that already expired are taken again if
+// the stock is still there; otherwise the error wraps ErrInsufficientStock.
+func HoldReservations(checkoutID string) error {
+	return db.Db.Transaction(func(tx *gorm.DB) error {
+		var reservations []models.StockReservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("checkout_id = ?", checkoutID).
+			Find(&reservations).Error; err != nil {
+			return err
+		}
+		if len(reservations) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		expires := time.Now().Add(ReservationTTL)
+		for _, reservation := range reservations {
+			switch reservation.Status {
+			case ReservationCommitted:
+				return ErrReservationCommitted
+			case ReservationReleased:
+				if err := AdjustStock(tx, reservation.ProductID, reservation.VariantID, -reservation.Quantity,
+					StockChange{Reason: StockReserved, CheckoutID: checkoutID}); err != nil {
+					if errors.Is(err, ErrInsufficientStock) {
+						return fmt.Errorf("product %d: %w", reservation.ProductID, err)
+					}
+					return err
+				}
+			}
+			if err := tx.Model(&reservation).Updates(map[string]any{
+				"status":     ReservationHeld,
+				"expires_at": expires,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseExpiredReservations gives back stock held by checkouts whose buyer
+// never came back, and marks their pending PayPal orders as expired.
+func ReleaseExpiredReservations() error {
+	var checkoutIDs []string
+	if err := db.Db.Model(&models.StockReservation{}).
+		Where("status = ? AND expires_at < ?", ReservationHeld, time.Now()).
+		Distinct().Pluck("checkout_id", &checkoutIDs).Error; err != nil {
+		return err
+	}
+
+	for _, checkoutID := range checkoutIDs {
+		err := db.Db.Transaction(func(tx *gorm.DB) error {
+			if err := ReleaseReservations(tx, checkoutID); err != nil {
+				return err
+			}
+			return tx.Model(&models.TempOrder{}).
+				Where("order_id = ? AND status = ?", checkoutID, "Pending").
+				Updates(map[string]any{"status": "Expired", "payment_status": "Expired"}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
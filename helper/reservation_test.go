@@ -0,0 +1,111 @@
+package helper
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []ReservationLine
+		want  []ReservationLine
+	}{
+		{
+			name:  "distinct lines kept",
+			lines: []ReservationLine{{1, 0, 2}, {2, 0, 1}},
+			want:  []ReservationLine{{1, 0, 2}, {2, 0, 1}},
+		},
+		{
+			name:  "same product added up",
+			lines: []ReservationLine{{1, 0, 2}, {2, 0, 1}, {1, 0, 3}},
+			want:  []ReservationLine{{1, 0, 5}, {2, 0, 1}},
+		},
+		{
+			name:  "variants kept apart",
+			lines: []ReservationLine{{1, 7, 1}, {1, 8, 1}, {1, 0, 1}, {1, 7, 2}},
+			want:  []ReservationLine{{1, 7, 3}, {1, 8, 1}, {1, 0, 1}},
+		},
+		{
+			name:  "bundle and single line of one product",
+			lines: []ReservationLine{{4, 0, 1}, {5, 0, 2}, {4, 0, 1}, {5, 0, 2}},
+			want:  []ReservationLine{{4, 0, 2}, {5, 0, 4}},
+		},
+		{
+			name:  "no lines",
+			lines: nil,
+			want:  nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeLines(tt.lines); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergeLines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReserveLines(t *testing.T) {
+	errDatabase := errors.New("connection reset")
+	tests := []struct {
+		name      string
+		lines     []ReservationLine
+		failOn    int
+		failWith  error
+		wantTaken []int
+		wantErr   error
+	}{
+		{
+			name:      "all lines taken",
+			lines:     []ReservationLine{{1, 0, 1}, {2, 0, 1}, {3, 0, 1}},
+			wantTaken: []int{1, 2, 3},
+		},
+		{
+			name:      "stops at the short line",
+			lines:     []ReservationLine{{1, 0, 1}, {2, 0, 5}, {3, 0, 1}},
+			failOn:    2,
+			failWith:  ErrInsufficientStock,
+			wantTaken: []int{1},
+			wantErr:   ErrInsufficientStock,
+		},
+		{
+			name:      "first line short takes nothing",
+			lines:     []ReservationLine{{1, 0, 9}, {2, 0, 1}},
+			failOn:    1,
+			failWith:  ErrInsufficientStock,
+			wantTaken: nil,
+			wantErr:   ErrInsufficientStock,
+		},
+		{
+			name:      "other errors passed through",
+			lines:     []ReservationLine{{1, 0, 1}, {2, 0, 1}},
+			failOn:    2,
+			failWith:  errDatabase,
+			wantTaken: []int{1},
+			wantErr:   errDatabase,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var taken []int
+			err := reserveLines(tt.lines, func(line ReservationLine) error {
+				if line.ProductID == tt.failOn {
+					return tt.failWith
+				}
+				taken = append(taken, line.ProductID)
+				return nil
+			})
+
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Fatalf("reserveLines() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(taken, tt.wantTaken) {
+				t.Errorf("took products %v, want %v", taken, tt.wantTaken)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package helper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// PrefixTSQuery turns free text into a to_tsquery expression that matches
+// every word as a prefix, e.g. "leather sof" becomes "leather:* & sof:*".
+// It returns "" when the text has no searchable words.
+func PrefixTSQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, strings.ToLower(word)+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// SortDirection maps a user supplied order to ASC or DESC, defaulting to
+// fallback for anything else.
+func SortDirection(order, fallback string) string {
+	switch strings.ToLower(order) {
+	case "asc":
+		return "ASC"
+	case "desc":
+		return "DESC"
+	default:
+		return fallback
+	}
+}
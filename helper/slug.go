@@ -0,0 +1,200 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	db "admin/DB"
+	"admin/models"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+const (
+	SlugProduct  = "product"
+	SlugCategory = "category"
+
+	maxSlugLength = 80
+)
+
+var (
+	ErrInvalidSlug = errors.New("slug may only contain lowercase letters, digits and hyphens")
+	ErrSlugTaken   = errors.New("slug is already in use")
+)
+
+var slugTables = map[string]struct{ table, id string }{
+	SlugProduct:  {"products", "product_id"},
+	SlugCategory: {"categories", "category_id"},
+}
+
+// Slugify turns a name into a URL-safe slug: "Oak Dining Table (6 seater)"
+// becomes "oak-dining-table-6-seater". Accents are dropped.
+func Slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFKD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		slug = "item"
+	}
+	return slug
+}
+
+// ValidSlug reports whether slug is already in canonical form.
+func ValidSlug(slug string) bool {
+	return slug != "" && len(slug) <= maxSlugLength && Slugify(slug) == slug
+}
+
+// UniqueSlug returns base, or base with a numeric suffix, that no other
+// entity of the kind uses as its slug or as an old slug that redirects.
+func UniqueSlug(tx *gorm.DB, kind, base string, entityID any) (string, error) {
+	for n := 1; ; n++ {
+		slug := slugCandidate(base, n)
+		taken, err := slugTaken(tx, kind, slug, entityID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+	}
+}
+
+// slugCandidate is the nth slug UniqueSlug tries for base: base itself, then
+// base-2, base-3 and so on, shortened so the suffix fits in maxSlugLength.
+func slugCandidate(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	suffix := fmt.Sprintf("-%d", n)
+	return strings.TrimRight(base[:min(len(base), maxSlugLength-len(suffix))], "-") + suffix
+}
+
+func slugTaken(tx *gorm.DB, kind, slug string, entityID any) (bool, error) {
+	meta := slugTables[kind]
+
+	var count int64
+	if err := tx.Unscoped().Table(meta.table).
+		Where("slug = ? AND "+meta.id+" <> ?", slug, entityID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := tx.Model(&models.SlugRedirect{}).
+		Where("entity_type = ? AND old_slug = ? AND entity_id <> ?", kind, slug, entityID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PickSlug checks a slug an admin asked for, or derives a free one from name
+// when none was given.
+func PickSlug(tx *gorm.DB, kind, requested, name string, entityID any) (string, error) {
+	if requested == "" {
+		return UniqueSlug(tx, kind, Slugify(name), entityID)
+	}
+	if !ValidSlug(requested) {
+		return "", ErrInvalidSlug
+	}
+	taken, err := slugTaken(tx, kind, requested, entityID)
+	if err != nil {
+		return "", err
+	}
+	if taken {
+		return "", ErrSlugTaken
+	}
+	return requested, nil
+}
+
+// ChangeSlug stores newSlug on the entity and keeps oldSlug resolving to it.
+// A slug the entity used before and now takes back stops being a redirect.
+func ChangeSlug(tx *gorm.DB, kind string, entityID int, oldSlug, newSlug string) error {
+	if oldSlug == newSlug {
+		return nil
+	}
+	meta := slugTables[kind]
+
+	if err := tx.Table(meta.table).Where(meta.id+" = ?", entityID).Update("slug", newSlug).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("entity_type = ? AND old_slug = ?", kind, newSlug).Delete(&models.SlugRedirect{}).Error; err != nil {
+		return err
+	}
+	if oldSlug == "" {
+		return nil
+	}
+	return tx.Create(&models.SlugRedirect{EntityType: kind, OldSlug: oldSlug, EntityID: entityID}).Error
+}
+
+// ResolveRedirect returns the entity an old slug used to point to.
+func ResolveRedirect(kind, slug string) (int, bool) {
+	var redirect models.SlugRedirect
+	if err := db.Db.Where("entity_type = ? AND old_slug = ?", kind, slug).First(&redirect).Error; err != nil {
+		return 0, false
+	}
+	return redirect.EntityID, true
+}
+
+// BackfillSlugs gives every product and category without a slug one derived
+// from its name.
+func BackfillSlugs() error {
+	var products []models.Product
+	if err := db.Db.Unscoped().Where("slug IS NULL OR slug = ''").Find(&products).Error; err != nil {
+		return err
+	}
+	for _, product := range products {
+		slug, err := UniqueSlug(db.Db, SlugProduct, Slugify(product.ProductName), product.ProductID)
+		if err != nil {
+			return err
+		}
+		if err := db.Db.Unscoped().Model(&product).Update("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+
+	var categories []models.Category
+	if err := db.Db.Unscoped().Where("slug IS NULL OR slug = ''").Find(&categories).Error; err != nil {
+		return err
+	}
+	for _, category := range categories {
+		slug, err := UniqueSlug(db.Db, SlugCategory, Slugify(category.CategoryName), category.CategoryID)
+		if err != nil {
+			return err
+		}
+		if err := db.Db.Unscoped().Model(&category).Update("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProductURL and CategoryURL are the canonical storefront paths.
+func ProductURL(slug string) string {
+	return "/products/slug/" + slug
+}
+
+func CategoryURL(slug string) string {
+	return "/categories/slug/" + slug
+}
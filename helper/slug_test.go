@@ -0,0 +1,62 @@
+package helper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Oak Dining Table (6 seater)", "oak-dining-table-6-seater"},
+		{"accents dropped", "Crème Brûlée Café Chair", "creme-brulee-cafe-chair"},
+		{"compatibility forms", "Ｓｏｆａ ½ price", "sofa-1-2-price"},
+		{"punctuation collapsed", "  --Teak / Rosewood!!  ", "teak-rosewood"},
+		{"non latin only", "木製テーブル", "item"},
+		{"empty", "", "item"},
+		{"truncated without trailing dash", strings.Repeat("a", 79) + " b", strings.Repeat("a", 79)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.in); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugCandidate(t *testing.T) {
+	long := strings.Repeat("a", maxSlugLength)
+	tests := []struct {
+		name string
+		base string
+		n    int
+		want string
+	}{
+		{"first try is the base", "oak-table", 1, "oak-table"},
+		{"suffix", "oak-table", 2, "oak-table-2"},
+		{"double digit suffix", "oak-table", 12, "oak-table-12"},
+		{"long base shortened for suffix", long, 2, strings.Repeat("a", maxSlugLength-2) + "-2"},
+		{"long base shortened further", long, 10, strings.Repeat("a", maxSlugLength-3) + "-10"},
+		{"no dash left before suffix", strings.Repeat("a", maxSlugLength-3) + "-bb", 2, strings.Repeat("a", maxSlugLength-3) + "-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slugCandidate(tt.base, tt.n)
+			if got != tt.want {
+				t.Errorf("slugCandidate(%q, %d) = %q, want %q", tt.base, tt.n, got, tt.want)
+			}
+			if len(got) > maxSlugLength {
+				t.Errorf("slugCandidate(%q, %d) is %d bytes, longer than %d", tt.base, tt.n, len(got), maxSlugLength)
+			}
+			if !ValidSlug(got) {
+				t.Errorf("slugCandidate(%q, %d) = %q is not a valid slug", tt.base, tt.n, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+
+	db "admin/DB"
+	"admin/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// Reasons recorded on stock movements.
+const (
+	StockInitial       = "initial"
+	StockOpening       = "opening_balance"
+	StockAdminSet      = "admin_set"
+	StockImport        = "import"
+	StockReserved      = "reserved"
+	StockReleased      = "released"
+	StockSold          = "sold"
+	StockOrderCanceled = "order_canceled"
+	StockReturned      = "returned"
+)
+
+// StockChange says why stock moved and what caused it.
+type StockChange struct {
+	Reason     string
+	OrderID    int
+	CheckoutID string
+	AdminID    uint
+}
+
+// AdjustStock adds delta (negative to take stock) to the product, or to the
+// variant when variantID is set, and records the movement in the same
+// transaction. Taking more than is available fails with ErrInsufficientStock
+// and changes nothing.
+func AdjustStock(tx *gorm.DB, productID, variantID, delta int, change StockChange) error {
+	return tx.Transaction(func(tx *gorm.DB) error {
+		query := stockQuery(tx, productID, variantID)
+		if delta < 0 {
+			query = query.Where("quantity >= ?", -delta)
+		}
+
+		result := query.Update("quantity", gorm.Expr("quantity + ?", delta))
+		if result.Error != nil {
+			return fmt.Errorf("cannot update stock: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			if delta < 0 {
+				return ErrInsufficientStock
+			}
+			return gorm.ErrRecordNotFound
+		}
+		return recordMovement(tx, productID, variantID, delta, change)
+	})
+}
+
+// SetStock overwrites the quantity after a stock count and records the
+// difference. It returns the quantity before the change.
+func SetStock(tx *gorm.DB, productID, variantID, quantity int, change StockChange) (int, error) {
+	var previous int
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var current []int
+		if err := stockQuery(tx, productID, variantID).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Pluck("quantity", &current).Error; err != nil {
+			return err
+		}
+		if len(current) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		previous = current[0]
+		if previous == quantity {
+			return nil
+		}
+
+		if err := stockQuery(tx, productID, variantID).Update("quantity", quantity).Error; err != nil {
+			return fmt.Errorf("cannot update stock: %w", err)
+		}
+		return recordMovement(tx, productID, variantID, quantity-previous, change)
+	})
+	return previous, err
+}
+
+// RecordStock logs the quantity a new product or variant starts with.
+func RecordStock(tx *gorm.DB, productID, variantID, quantity int, change StockChange) error {
+	if quantity == 0 {
+		return nil
+	}
+	return recordMovement(tx, productID, variantID, quantity, change)
+}
+
+func stockQuery(tx *gorm.DB, productID, variantID int) *gorm.DB {
+	if variantID != 0 {
+		return tx.Model(&models.ProductVariant{}).Where("variant_id = ? AND product_id = ?", variantID, productID)
+	}
+	return tx.Model(&models.Product{}).Where("product_id = ?", productID)
+}
+
+func recordMovement(tx *gorm.DB, productID, variantID, delta int, change StockChange) error {
+	var quantity []int
+	if err := stockQuery(tx, productID, variantID).Pluck("quantity", &quantity).Error; err != nil {
+		return err
+	}
+	if len(quantity) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	movement := models.StockMovement{
+		ProductID:  productID,
+		VariantID:  variantID,
+		Delta:      delta,
+		Quantity:   quantity[0],
+		Reason:     change.Reason,
+		OrderID:    change.OrderID,
+		CheckoutID: change.CheckoutID,
+	}
+	if change.AdminID != 0 {
+		movement.AdminID = &change.AdminID
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return fmt.Errorf("cannot record stock movement: %w", err)
+	}
+	return nil
+}
+
+// BackfillStockLedger opens the ledger of every product and variant that has
+// no movements yet with its current quantity, so later movements add up.
+func BackfillStockLedger() error {
+	return db.Db.Exec(`
+		INSERT INTO stock_movements (product_id, variant_id, delta, quantity, reason, order_id, checkout_id, created_at)
+		SELECT p.product_id, 0, p.quantity, p.quantity, ?, 0, '', NOW() FROM products p
+		WHERE p.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.product_id AND m.variant_id = 0)
+		UNION ALL
+		SELECT v.product_id, v.variant_id, v.quantity, v.quantity, ?, 0, '', NOW() FROM product_variants v
+		WHERE v.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = v.product_id AND m.variant_id = v.variant_id)
+	`, StockOpening, StockOpening).Error
+}
+
+// FindVariant loads the variant and checks that it belongs to productID.
+func FindVariant(tx *gorm.DB, productID, variantID int) (models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := tx.Where("variant_id = ? AND product_id = ?", variantID, productID).First(&variant).Error
+	return variant, err
+}
+
+// UnitPrice is the list price of the product, or of the variant when one is
+// given, before offers.
+func UnitPrice(product models.Product, variant *models.ProductVariant) float64 {
+	if variant == nil {
+		return product.Price
+	}
+	return product.Price + variant.PriceDelta
+}
+
+// OfferPercentage returns the offer for the variant if it has its own,
+// otherwise the product-wide offer, or 0 when there is none.
+func OfferPercentage(tx *gorm.DB, productID, variantID int) int {
+	var offer models.Offer
+	query := tx.Where("product_id = ?", productID)
+	if variantID != 0 {
+		query = query.Where("variant_id IN (?, 0)", variantID).Order("variant_id DESC")
+	} else {
+		query = query.Where("variant_id = 0")
+	}
+
+	if err := query.First(&offer).Error; err != nil {
+		return 0
+	}
+	return offer.OfferPercentage
+}
@@ -0,0 +1,128 @@
+package helper
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	db "admin/DB"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MinSuggestLength = 2
+
+	maxProductSuggestions  = 8
+	maxCategorySuggestions = 5
+	maxQuerySuggestions    = 5
+	maxSearchTermLength    = 100
+
+	suggestCacheTTL  = 2 * time.Minute
+	suggestCacheSize = 5000
+)
+
+type suggestEntry struct {
+	suggestions responsemodels.Suggestions
+	expires     time.Time
+}
+
+var suggestCache = struct {
+	sync.RWMutex
+	entries map[string]suggestEntry
+}{entries: map[string]suggestEntry{}}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// NormalizeTerm lowercases a query and collapses its whitespace so that
+// "Oak  Table" and "oak table" count as the same search.
+func NormalizeTerm(query string) string {
+	term := strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	// The column counts characters, and cutting bytes could split one.
+	if runes := []rune(term); len(runes) > maxSearchTermLength {
+		term = strings.TrimSpace(string(runes[:maxSearchTermLength]))
+	}
+	return term
+}
+
+// RecordSearch counts a search and remembers how many products it found.
+func RecordSearch(query string, results int) error {
+	term := NormalizeTerm(query)
+	if len(term) < MinSuggestLength {
+		return nil
+	}
+	return db.Db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "term"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"hits":             gorm.Expr("search_terms.hits + 1"),
+			"results":          results,
+			"last_searched_at": time.Now(),
+		}),
+	}).Create(&models.SearchTerm{Term: term, Hits: 1, Results: results, LastSearchedAt: time.Now()}).Error
+}
+
+// Suggest returns what the search box offers while the shopper types:
+// published products whose name matches the prefix, in-stock ones first,
+// matching categories and popular past queries that found something.
+// Answers are cached per prefix for suggestCacheTTL.
+func Suggest(query string) (responsemodels.Suggestions, error) {
+	prefix := NormalizeTerm(query)
+
+	suggestCache.RLock()
+	entry, ok := suggestCache.entries[prefix]
+	suggestCache.RUnlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.suggestions, nil
+	}
+
+	suggestions := responsemodels.Suggestions{
+		Products:   []responsemodels.ProductSuggestion{},
+		Categories: []responsemodels.CategorySuggestion{},
+		Queries:    []string{},
+	}
+	like := likeEscaper.Replace(prefix) + "%"
+	wordLike := "% " + like
+
+	if err := db.Db.Model(&models.Product{}).Scopes(Published).
+		Select(`products.product_id, products.product_name, products.slug, products.img_url, products.price,
+			(products.quantity > 0 OR EXISTS (SELECT 1 FROM product_variants v
+				WHERE v.product_id = products.product_id AND v.quantity > 0 AND v.deleted_at IS NULL)) AS in_stock`).
+		Where("products.product_name ILIKE ? OR products.product_name ILIKE ? OR ? <% products.product_name", like, wordLike, prefix).
+		Order("in_stock DESC").
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "products.product_name ILIKE ? DESC", Vars: []any{like}}}).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "word_similarity(?, products.product_name) DESC", Vars: []any{prefix}}}).
+		Order("products.popularity DESC").
+		Limit(maxProductSuggestions).
+		Scan(&suggestions.Products).Error; err != nil {
+		return suggestions, err
+	}
+
+	if err := db.Db.Model(&models.Category{}).
+		Select("category_id, category_name AS name, slug").
+		Where("category_name ILIKE ? OR category_name ILIKE ?", like, wordLike).
+		Order("category_name ASC").
+		Limit(maxCategorySuggestions).
+		Scan(&suggestions.Categories).Error; err != nil {
+		return suggestions, err
+	}
+
+	if err := db.Db.Model(&models.SearchTerm{}).
+		Where("term LIKE ? AND term <> ? AND results > 0", like, prefix).
+		Order("hits DESC").
+		Limit(maxQuerySuggestions).
+		Pluck("term", &suggestions.Queries).Error; err != nil {
+		return suggestions, err
+	}
+
+	suggestCache.Lock()
+	if len(suggestCache.entries) >= suggestCacheSize {
+		suggestCache.entries = map[string]suggestEntry{}
+	}
+	suggestCache.entries[prefix] = suggestEntry{suggestions: suggestions, expires: time.Now().Add(suggestCacheTTL)}
+	suggestCache.Unlock()
+
+	return suggestions, nil
+}
@@ -23,6 +23,10 @@ func ValidateAll(input any) (string, error) {
 				return "phone number must be exactly 10 digits", fmt.Errorf("invalid phone number")
 			case "Password":
 				return "password must be between 8 and 32 characters", fmt.Errorf("invalid password")
+			case "Code":
+				return "OTP must be exactly 6 digits", fmt.Errorf("invalid otp")
+			case "VerifyVia":
+				return "verify_via must be email or sms", fmt.Errorf("invalid verification method")
 			default:
 				return "invalid input", fmt.Errorf("validation failed")
 			}
@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"time"
+
+	db "admin/DB"
+	"admin/models"
+)
+
+const (
+	// Repeat views of the same product by the same viewer within viewDedupWindow
+	// only move the view forward instead of counting again.
+	viewDedupWindow   = 30 * time.Minute
+	popularityWindow  = 30 * 24 * time.Hour
+	viewRetention     = 90 * 24 * time.Hour
+	MaxRecentlyViewed = 20
+)
+
+// RecordView logs that a user (userID != 0) or an anonymous session viewed
+// productID.
+func RecordView(productID int, userID uint, sessionID string) error {
+	query := db.Db.Model(&models.ProductView{}).Where("product_id = ? AND viewed_at > ?", productID, time.Now().Add(-viewDedupWindow))
+	view := models.ProductView{ProductID: productID, SessionID: sessionID, ViewedAt: time.Now()}
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+		view.UserID = &userID
+	} else if sessionID != "" {
+		query = query.Where("user_id IS NULL AND session_id = ?", sessionID)
+	} else {
+		return nil
+	}
+
+	result := query.Update("viewed_at", view.ViewedAt)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+	return db.Db.Create(&view).Error
+}
+
+// ClaimSessionViews hands the anonymous views of sessionID to userID once the
+// shopper logs in.
+func ClaimSessionViews(userID uint, sessionID string) error {
+	if userID == 0 || sessionID == "" {
+		return nil
+	}
+	return db.Db.Model(&models.ProductView{}).
+		Where("session_id = ? AND user_id IS NULL", sessionID).
+		Update("user_id", userID).Error
+}
+
+// RefreshPopularity sets each product's popularity to its view count over the
+// last 30 days and drops views older than the retention period.
+func RefreshPopularity() error {
+	if err := db.Db.Exec(`
+		UPDATE products SET popularity = COALESCE((
+			SELECT COUNT(*) FROM product_views v
+			WHERE v.product_id = products.product_id AND v.viewed_at > ?
+		), 0)
+	`, time.Now().Add(-popularityWindow)).Error; err != nil {
+		return err
+	}
+	return db.Db.Where("viewed_at < ?", time.Now().Add(-viewRetention)).Delete(&models.ProductView{}).Error
+}
@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"time"
+
+	"admin/helper"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Start launches the background jobs. It must run after the database is
+// initialised.
+func Start() {
+	Every("purge-stale-otps", 15*time.Minute, helper.PurgeStaleOTPs)
+	Every("publish-scheduled-products", time.Minute, helper.PublishScheduledProducts)
+	Every("release-expired-reservations", time.Minute, helper.ReleaseExpiredReservations)
+	Every("refresh-product-affinity", 6*time.Hour, helper.RefreshProductAffinity)
+	Every("refresh-popularity", time.Hour, helper.RefreshPopularity)
+}
+
+// Every runs fn immediately and then once per interval in its own goroutine.
+// Errors and panics are logged and never stop the loop.
+func Every(name string, interval time.Duration, fn func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			run(name, fn)
+			<-ticker.C
+		}
+	}()
+}
+
+func run(name string, fn func() error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{
+				"job":   name,
+				"panic": r,
+			}).Error("job panicked")
+		}
+	}()
+
+	if err := fn(); err != nil {
+		log.WithFields(log.Fields{
+			"job":   name,
+			"error": err,
+		}).Error("job failed")
+	}
+}
@@ -2,13 +2,20 @@ package main
 
 import (
 	db "admin/DB"
+	"admin/helper"
+	"admin/jobs"
+	"admin/notify"
 	"admin/route"
+	"admin/storage"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 )
 
 func main() {
-	db.InitDatabase()
+	db.InitDatabase(helper.BackfillSlugs, helper.BackfillPriceHistory, helper.BackfillStockLedger)
+	notify.Init()
+	storage.Init()
+	jobs.Start()
 
 	log.SetFormatter(&log.JSONFormatter{})
 	log.SetLevel(log.InfoLevel)
@@ -49,12 +49,7 @@ func AuthMiddleware(requiredRole string) gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return Secret, nil
-		})
+		token, err := parseToken(tokenString)
 
 		if err != nil || !token.Valid {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
@@ -79,6 +74,31 @@ func AuthMiddleware(requiredRole string) gin.HandlerFunc {
 	}
 }
 
+// OptionalAuth sets the claims when a valid token for requiredRole is sent
+// and otherwise lets the request through anonymously.
+func OptionalAuth(requiredRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString != "" {
+			if token, err := parseToken(tokenString); err == nil && token.Valid {
+				if claims, ok := token.Claims.(*Claims); ok && claims.Role == requiredRole {
+					c.Set("claims", claims)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+func parseToken(tokenString string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return Secret, nil
+	})
+}
+
 func GetClaims(c *gin.Context) (*Claims, error) {
 	claims, exists := c.Get("claims")
 	if !exists {
@@ -94,3 +114,14 @@ func GetClaims(c *gin.Context) (*Claims, error) {
 
 	return customClaims, nil
 }
+
+// CurrentID returns the user or admin ID from the token claims, or 0 when
+// the request carries none.
+func CurrentID(c *gin.Context) uint {
+	if claims, ok := c.Get("claims"); ok {
+		if customClaims, ok := claims.(*Claims); ok {
+			return customClaims.ID
+		}
+	}
+	return 0
+}
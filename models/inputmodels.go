@@ -7,6 +7,7 @@ type SignupInput struct {
 	Email       string `json:"email" validate:"required,email"`
 	PhoneNumber string `json:"phonenumber" validate:"required,len=10,numeric"`
 	Password    string `json:"password" validate:"required,min=8,max=32"`
+	VerifyVia   string `json:"verify_via" validate:"omitempty,oneof=email sms"`
 }
 
 type VerifyOTP struct {
@@ -19,6 +20,15 @@ type LoginInput struct {
 	Password string `json:"password" validate:"required,min=8,max=32"`
 }
 
+type PhoneOTPRequest struct {
+	PhoneNumber string `json:"phonenumber" validate:"required,len=10,numeric"`
+}
+
+type PhoneOTPLogin struct {
+	PhoneNumber string `json:"phonenumber" validate:"required,len=10,numeric"`
+	Code        string `json:"code" validate:"required,len=6,numeric"`
+}
+
 type SearchProduct struct {
 	Name string `json:"name" binding:"required"`
 }
@@ -53,6 +63,7 @@ type OrderInput struct {
 
 type CartInput struct {
 	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id"`
 	Quantity  int `json:"quantity"`
 }
 type ReviewInput struct {
@@ -80,5 +91,63 @@ type ReturnOrder struct {
 
 type OfferInput struct {
 	ProductID       int `json:"product_id"`
+	VariantID       int `json:"variant_id"`
 	OfferPercentage int `json:"offer_percentage"`
 }
+
+type VariantInput struct {
+	SKU        string   `json:"sku"`
+	Color      string   `json:"color"`
+	Fabric     string   `json:"fabric"`
+	Size       string   `json:"size"`
+	PriceDelta float64  `json:"price_delta"`
+	Quantity   int      `json:"quantity"`
+	Images     []string `json:"images"`
+}
+
+type AttributeInput struct {
+	Name     string   `json:"name" binding:"required"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type" binding:"required,oneof=number enum boolean"`
+	Unit     string   `json:"unit"`
+	Options  []string `json:"options"`
+	Required bool     `json:"required"`
+}
+
+type BrandInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	LogoURL     string `json:"logo_url" binding:"omitempty,url"`
+	Description string `json:"description"`
+	Country     string `json:"country" binding:"max=60"`
+}
+
+type QuestionInput struct {
+	Body string `json:"body" binding:"required,min=10,max=1000"`
+}
+
+type AnswerInput struct {
+	Body string `json:"body" binding:"required,min=2,max=2000"`
+}
+
+type ModerationInput struct {
+	Status string `json:"status" binding:"required,oneof=visible hidden"`
+}
+
+type BundleInput struct {
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Price       float64                `json:"price" binding:"required,gt=0"`
+	ImgURL      string                 `json:"img_url"`
+	Components  []BundleComponentInput `json:"components" binding:"required,min=2,dive"`
+}
+
+type BundleComponentInput struct {
+	ProductID int `json:"product_id" binding:"required"`
+	VariantID int `json:"variant_id"`
+	Quantity  int `json:"quantity" binding:"required,min=1"`
+}
+
+type BundleCartInput struct {
+	BundleID int `json:"bundle_id" binding:"required"`
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
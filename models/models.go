@@ -39,26 +39,195 @@ type Admin struct {
 type Category struct {
 	CategoryID   uint   `gorm:"primaryKey" json:"category_id"`
 	CategoryName string `json:"name"`
+	Slug         string `gorm:"type:varchar(100);uniqueIndex" json:"slug"`
+	ParentID     *uint  `gorm:"index" json:"parent_id"`
 	CreatedAt    time.Time
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
 }
 
+type Brand struct {
+	BrandID     uint           `gorm:"primaryKey" json:"brand_id"`
+	Name        string         `gorm:"not null" json:"name"`
+	LogoURL     string         `json:"logo_url"`
+	Description string         `json:"description"`
+	Country     string         `json:"country"`
+	CreatedAt   time.Time      `json:"created_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// SearchTerm counts how often a normalised search query was run and how many
+// products it found the last time, for popular-query suggestions.
+type SearchTerm struct {
+	Term           string    `gorm:"primaryKey;type:varchar(100)" json:"term"`
+	Hits           int       `gorm:"default:0" json:"hits"`
+	Results        int       `gorm:"default:0" json:"results"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// SlugRedirect keeps a slug an entity used before a rename pointing at it.
+type SlugRedirect struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	EntityType string `gorm:"type:varchar(16);not null;uniqueIndex:idx_slug_redirect" json:"entity_type"`
+	OldSlug    string `gorm:"type:varchar(100);not null;uniqueIndex:idx_slug_redirect" json:"old_slug"`
+	EntityID   int    `gorm:"not null;index" json:"entity_id"`
+	CreatedAt  time.Time
+}
+
+// CategoryAttribute describes a typed specification products of a category
+// (and of its subcategories) carry, e.g. width in cm or the frame material.
+type CategoryAttribute struct {
+	AttributeID int      `gorm:"primaryKey;autoIncrement" json:"attribute_id"`
+	CategoryID  uint     `gorm:"not null;uniqueIndex:idx_category_attribute" json:"category_id"`
+	Name        string   `gorm:"not null;uniqueIndex:idx_category_attribute" json:"name"`
+	Label       string   `json:"label"`
+	Type        string   `gorm:"not null" json:"type"`
+	Unit        string   `json:"unit,omitempty"`
+	Options     []string `gorm:"serializer:json" json:"options,omitempty"`
+	Required    bool     `gorm:"default:false" json:"required"`
+	CreatedAt   time.Time
+}
+
+type Breadcrumb struct {
+	CategoryID uint   `json:"category_id"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+}
+
 type Product struct {
 	ProductID     int            `gorm:"primaryKey;autoIncrement" json:"product_id"`
+	SKU           *string        `gorm:"uniqueIndex" json:"sku"`
 	ProductName   string         `json:"name"`
+	Slug          string         `gorm:"type:varchar(100);uniqueIndex" json:"slug"`
 	Description   string         `json:"description"`
 	Price         float64        `json:"price"`
 	CategoryID    uint           `gorm:"not null;index;constraint:OnDelete:CASCADE" json:"category_id"`
+	BrandID       *uint          `gorm:"index" json:"brand_id"`
 	ImgURL        string         `json:"img_url"`
 	Status        int            `gorm:"type:smallint;default:1" json:"status"`
+	Lifecycle     string         `gorm:"type:varchar(16);default:active;index" json:"lifecycle"`
+	PublishAt     *time.Time     `json:"publish_at"`
 	Quantity      int            `json:"quantity" gorm:"default:0"`
+	OfferDiscount int            `gorm:"default:0" json:"offer_discount"`
+	Popularity    int            `gorm:"default:0;index" json:"popularity"`
+	Attributes    map[string]any `gorm:"type:jsonb;serializer:json" json:"attributes"`
 	AverageRating float64        `gorm:"-" json:"average_rating"`
 	TotalReviews  int            `gorm:"-" json:"total_reviews"`
 	RecentReviews []ReviewRating `gorm:"foreignKey:ProductID;references:ProductID" json:"recent_reviews"`
+	Breadcrumbs   []Breadcrumb   `gorm:"-" json:"breadcrumbs,omitempty"`
+	Snippet       string         `gorm:"->;-:migration" json:"snippet,omitempty"`
+	Relevance     float64        `gorm:"->;-:migration" json:"relevance,omitempty"`
 	CreatedAt     time.Time
 	DeletedAt     gorm.DeletedAt `gorm:"index"`
 }
 
+type ProductVariant struct {
+	VariantID  int            `gorm:"primaryKey;autoIncrement" json:"variant_id"`
+	ProductID  int            `gorm:"not null;index" json:"product_id"`
+	SKU        string         `gorm:"uniqueIndex;not null" json:"sku"`
+	Color      string         `json:"color"`
+	Fabric     string         `json:"fabric"`
+	Size       string         `json:"size"`
+	PriceDelta float64        `gorm:"default:0" json:"price_delta"`
+	Quantity   int            `gorm:"default:0" json:"quantity"`
+	Images     []string       `gorm:"serializer:json" json:"images"`
+	CreatedAt  time.Time      `json:"created_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type ProductQuestion struct {
+	QuestionID uint      `gorm:"primaryKey" json:"question_id"`
+	ProductID  int       `gorm:"not null;index" json:"product_id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	Status     string    `gorm:"type:varchar(16);not null;default:visible;index" json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ProductAnswer is written either by an admin (AdminID set) or by a customer
+// who received the product (UserID set).
+type ProductAnswer struct {
+	AnswerID   uint      `gorm:"primaryKey" json:"answer_id"`
+	QuestionID uint      `gorm:"not null;index" json:"question_id"`
+	UserID     *uint     `gorm:"index" json:"user_id,omitempty"`
+	AdminID    *uint     `json:"admin_id,omitempty"`
+	Body       string    `gorm:"type:text;not null" json:"body"`
+	Status     string    `gorm:"type:varchar(16);not null;default:visible;index" json:"status"`
+	Upvotes    int       `gorm:"not null;default:0" json:"upvotes"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type AnswerVote struct {
+	AnswerID  uint `gorm:"primaryKey"`
+	UserID    uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
+// ProductView records a shopper looking at a product. Anonymous views carry
+// only the session cookie and are claimed by the user on their next visit
+// after logging in.
+type ProductView struct {
+	ViewID    uint      `gorm:"primaryKey"`
+	ProductID int       `gorm:"not null;index"`
+	UserID    *uint     `gorm:"index"`
+	SessionID string    `gorm:"type:varchar(64);index"`
+	ViewedAt  time.Time `gorm:"not null;index"`
+}
+
+// PriceHistory records the price of a product, or of one of its variants,
+// each time the list price or the offer changes.
+type PriceHistory struct {
+	PriceHistoryID  uint      `gorm:"primaryKey" json:"price_history_id"`
+	ProductID       int       `gorm:"not null;index:idx_price_history" json:"product_id"`
+	VariantID       int       `gorm:"default:0;index:idx_price_history" json:"variant_id"`
+	Price           float64   `json:"price"`
+	OfferPercentage int       `json:"offer_percentage"`
+	FinalPrice      float64   `json:"final_price"`
+	AdminID         *uint     `json:"admin_id"`
+	Source          string    `gorm:"type:varchar(16)" json:"source"`
+	ChangedAt       time.Time `gorm:"index:idx_price_history" json:"changed_at"`
+}
+
+// ProductAffinity counts the orders in which two products were bought
+// together. It is rebuilt periodically from order history.
+type ProductAffinity struct {
+	ProductID int     `gorm:"primaryKey"`
+	RelatedID int     `gorm:"primaryKey"`
+	Score     float64 `gorm:"not null;index"`
+	UpdatedAt time.Time
+}
+
+// Bundle sells several products together, e.g. a table with six chairs, for
+// a single price. Its stock is derived from the components.
+type Bundle struct {
+	BundleID    int               `gorm:"primaryKey;autoIncrement" json:"bundle_id"`
+	Name        string            `gorm:"not null" json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `gorm:"not null" json:"price"`
+	ImgURL      string            `json:"img_url"`
+	Components  []BundleComponent `gorm:"foreignKey:BundleID" json:"components"`
+	CreatedAt   time.Time         `json:"created_at"`
+	DeletedAt   gorm.DeletedAt    `gorm:"index" json:"-"`
+}
+
+type BundleComponent struct {
+	ComponentID int `gorm:"primaryKey;autoIncrement" json:"component_id"`
+	BundleID    int `gorm:"not null;index" json:"bundle_id"`
+	ProductID   int `gorm:"not null" json:"product_id"`
+	VariantID   int `gorm:"default:0" json:"variant_id"`
+	Quantity    int `gorm:"not null" json:"quantity"`
+}
+
+type ProductImage struct {
+	ImageID      int       `gorm:"primaryKey;autoIncrement" json:"image_id"`
+	ProductID    int       `gorm:"not null;index" json:"product_id"`
+	Position     int       `gorm:"not null;default:0" json:"position"`
+	StorageKey   string    `gorm:"not null" json:"-"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	MediumURL    string    `json:"medium_url"`
+	LargeURL     string    `json:"large_url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type ReviewRating struct {
 	ReviewRatingID int       `gorm:"primaryKey;autoIncrement" json:"review_rating_id"`
 	UserID         int       `gorm:"not null;index" json:"user_id"`
@@ -72,6 +241,13 @@ type ReviewRating struct {
 	Product Product `gorm:"foreignKey:ProductID;references:ProductID"`
 }
 
+// CompareItem is a product on a user's saved comparison list.
+type CompareItem struct {
+	UserID    uint `gorm:"primaryKey"`
+	ProductID int  `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
 type Wishlist struct {
 	WishlistID  int `gorm:"primaryKey;autoIncrement"`
 	UserID      int `gorm:"not null;index;foreignKey:UserID;references:UserID"`
@@ -87,6 +263,8 @@ type Cart struct {
 	CartID    int `gorm:"primaryKey;autoIncrement"`
 	UserID    int `gorm:"not null;index"`
 	ProductID int `gorm:"not null"`
+	VariantID int `gorm:"default:0"`
+	BundleID  int `gorm:"default:0;index"`
 	Total     int
 	Quantity  int
 	User      User    `gorm:"foreignKey:UserID"`
@@ -110,10 +288,13 @@ type Order struct {
 }
 
 type OrderItem struct {
-	OrderItemsID int     `gorm:"primaryKey;autoIncrement"`
-	OrderID      int     `gorm:"not null;index"`
-	UserID       int     `gorm:"not null;index"`
-	ProductID    int     `gorm:"not null;index"`
+	OrderItemsID int `gorm:"primaryKey;autoIncrement"`
+	OrderID      int `gorm:"not null;index"`
+	UserID       int `gorm:"not null;index"`
+	ProductID    int `gorm:"not null;index"`
+	VariantID    int `gorm:"default:0"`
+	BundleID     int `gorm:"default:0"`
+	SKU          string
 	Quantity     int     `gorm:"default:0"`
 	Price        float64 `gorm:"not null"`
 	Discount     float64 `gorm:"default:0"`
@@ -136,9 +317,10 @@ type Coupon struct {
 type TempUser struct {
 	UserName    string `json:"username"`
 	Address     string
-	Email       string `json:"email"`
+	Email       string `json:"email" gorm:"index"`
 	Password    string
 	PhoneNumber string
+	CreatedAt   time.Time
 }
 
 type UserLoginMethod struct {
@@ -147,9 +329,16 @@ type UserLoginMethod struct {
 }
 
 type OTP struct {
-	Email  string
-	Code   string
-	Expiry time.Time
+	ID          uint   `gorm:"primaryKey"`
+	Recipient   string `gorm:"uniqueIndex:idx_otp_recipient_purpose"`
+	Purpose     string `gorm:"uniqueIndex:idx_otp_recipient_purpose"`
+	CodeHash    string `json:"-"`
+	Attempts    int    `gorm:"default:0"`
+	Expiry      time.Time
+	SendCount   int `gorm:"default:0"`
+	WindowStart time.Time
+	LastSentAt  time.Time
+	CreatedAt   time.Time
 }
 
 type Wallet struct {
@@ -163,6 +352,7 @@ type Wallet struct {
 type Offer struct {
 	gorm.Model
 	ProductID       int `gorm:"not null"`
+	VariantID       int `gorm:"default:0"`
 	OfferPercentage int `gorm:"not null"`
 }
 
@@ -174,6 +364,39 @@ type SalesReport struct {
 	CouponsDeduction float64          `json:"coupons_deduction"`
 	ProductSales     []ProductDetails `json:"product_sales" gorm:"-"`
 }
+
+// StockMovement is one entry of the append-only stock ledger. Delta is the
+// change and Quantity the stock left afterwards.
+type StockMovement struct {
+	MovementID uint      `gorm:"primaryKey" json:"movement_id"`
+	ProductID  int       `gorm:"not null;index:idx_stock_movement" json:"product_id"`
+	VariantID  int       `gorm:"default:0;index:idx_stock_movement" json:"variant_id"`
+	Delta      int       `gorm:"not null" json:"delta"`
+	Quantity   int       `gorm:"not null" json:"quantity"`
+	Reason     string    `gorm:"type:varchar(32);not null;index" json:"reason"`
+	OrderID    int       `gorm:"default:0;index" json:"order_id,omitempty"`
+	CheckoutID string    `gorm:"type:varchar(255)" json:"checkout_id,omitempty"`
+	AdminID    *uint     `json:"admin_id,omitempty"`
+	CreatedAt  time.Time `gorm:"index:idx_stock_movement" json:"created_at"`
+}
+
+// StockReservation holds stock for a checkout until the order is placed or
+// the reservation expires. CheckoutID is the PayPal order ID for PayPal
+// checkouts.
+type StockReservation struct {
+	ReservationID uint      `gorm:"primaryKey" json:"reservation_id"`
+	CheckoutID    string    `gorm:"type:varchar(255);not null;index" json:"checkout_id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	ProductID     int       `gorm:"not null;index" json:"product_id"`
+	VariantID     int       `gorm:"default:0" json:"variant_id"`
+	Quantity      int       `gorm:"not null" json:"quantity"`
+	Status        string    `gorm:"type:varchar(16);not null;index" json:"status"`
+	OrderID       int       `gorm:"default:0;index" json:"order_id,omitempty"`
+	ExpiresAt     time.Time `gorm:"index" json:"expires_at"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
 type TempOrder struct {
 	OrderID       string    `gorm:"primaryKey;type:varchar(255);not null"`
 	UserID        int       `gorm:"column:user_id"`
@@ -190,6 +413,19 @@ type TempOrder struct {
 	UpdatedAt     time.Time `gorm:"column:updated_at"`
 }
 
+// TempOrderItem is a priced line of a PayPal checkout, kept so the order
+// created on capture matches what the buyer approved.
+type TempOrderItem struct {
+	TempOrderItemID int    `gorm:"primaryKey;autoIncrement"`
+	TempOrderID     string `gorm:"type:varchar(255);not null;index"`
+	ProductID       int    `gorm:"not null"`
+	VariantID       int    `gorm:"default:0"`
+	BundleID        int    `gorm:"default:0"`
+	SKU             string
+	Quantity        int     `gorm:"not null"`
+	Price           float64 `gorm:"not null"`
+}
+
 type Invoice struct {
 	InvoiceID string        `json:"invoice_id"`
 	Date      time.Time     `json:"date"`
@@ -218,6 +454,18 @@ type WalletTransaction struct {
 	CreatedAt       time.Time `gorm:"autoCreateTime"`
 }
 
+type Notification struct {
+	NotificationID uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"not null;index"`
+	Type           string `gorm:"type:varchar(32);not null"`
+	Title          string `gorm:"type:varchar(255);not null"`
+	Body           string `gorm:"type:text"`
+	OrderID        int    `gorm:"default:null"`
+	ProductID      int    `gorm:"default:null"`
+	ReadAt         *time.Time
+	CreatedAt      time.Time `gorm:"index"`
+}
+
 type ProductDetails struct {
 	ProductID  uint
 	Category   string
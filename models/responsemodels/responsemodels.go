@@ -15,16 +15,183 @@ type User struct {
 type Products struct {
 	ProductID     int            `json:"product_id" gorm:"primaryKey;autoIncrement"`
 	ProductName   string         `json:"name" gorm:"column:product_name"`
+	Slug          string         `json:"slug"`
+	CanonicalURL  string         `json:"canonical_url"`
 	Description   string         `json:"description"`
 	Price         float64        `json:"price"`
 	OfferDiscount float64        `json:"offer_discount"`
+	LowestPrice   float64        `json:"lowest_price_30_days"`
 	CategoryID    uint           `json:"category_id"`
+	BrandID       *uint          `json:"brand_id"`
+	BrandName     string         `json:"brand_name,omitempty"`
+	Breadcrumbs   []Breadcrumb   `json:"breadcrumbs" gorm:"-"`
 	ImgURL        string         `json:"img_url"`
 	Status        string         `json:"status"`
 	Quantity      int            `json:"quantity"`
 	AverageRating float64        `json:"average_rating"`
 	TotalReviews  int            `json:"total_reviews"`
 	RecentReviews []ReviewRating `json:"recent_reviews" gorm:"foreignKey:ProductID"`
+	Variants      []Variant      `json:"variants,omitempty" gorm:"-"`
+	Images        []Image        `json:"images,omitempty" gorm:"-"`
+	Attributes    []Attribute    `json:"attributes,omitempty" gorm:"-"`
+	QuestionCount int            `json:"question_count"`
+	AnsweredCount int            `json:"answered_question_count"`
+}
+
+type Question struct {
+	QuestionID uint      `json:"question_id"`
+	ProductID  int       `json:"product_id"`
+	Body       string    `json:"body"`
+	Status     string    `json:"status,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Answers    []Answer  `json:"answers"`
+}
+
+type Answer struct {
+	AnswerID  uint      `json:"answer_id"`
+	Body      string    `json:"body"`
+	Answerer  string    `json:"answerer"`
+	Upvotes   int       `json:"upvotes"`
+	Status    string    `json:"status,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Attribute struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Value any    `json:"value"`
+	Unit  string `json:"unit,omitempty"`
+}
+
+type Bundle struct {
+	BundleID    int               `json:"bundle_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Price       float64           `json:"price"`
+	ListPrice   float64           `json:"list_price"`
+	ImgURL      string            `json:"img_url"`
+	Stock       int               `json:"stock"`
+	Components  []BundleComponent `json:"components"`
+}
+
+type BundleComponent struct {
+	ProductID   int     `json:"product_id"`
+	VariantID   int     `json:"variant_id,omitempty"`
+	SKU         string  `json:"sku,omitempty"`
+	ProductName string  `json:"name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+type RecentlyViewed struct {
+	ProductID     int       `json:"product_id"`
+	ProductName   string    `json:"name"`
+	ImgURL        string    `json:"img_url"`
+	Price         float64   `json:"price"`
+	OfferDiscount int       `json:"offer_discount"`
+	FinalPrice    float64   `json:"final_price"`
+	Quantity      int       `json:"quantity"`
+	InStock       bool      `json:"in_stock"`
+	ViewedAt      time.Time `json:"viewed_at"`
+}
+
+type RelatedProduct struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"name"`
+	Price       float64 `json:"price"`
+	ImgURL      string  `json:"img_url"`
+	Reason      string  `json:"reason"`
+}
+
+type Suggestions struct {
+	Products   []ProductSuggestion  `json:"products"`
+	Categories []CategorySuggestion `json:"categories"`
+	Queries    []string             `json:"queries"`
+}
+
+type ProductSuggestion struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"name"`
+	Slug        string  `json:"slug"`
+	ImgURL      string  `json:"img_url"`
+	Price       float64 `json:"price"`
+	InStock     bool    `json:"in_stock"`
+}
+
+type CategorySuggestion struct {
+	CategoryID uint   `json:"category_id"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+}
+
+type Comparison struct {
+	Products []ComparedProduct `json:"products"`
+	Rows     []ComparisonRow   `json:"rows"`
+}
+
+type ComparedProduct struct {
+	ProductID   int    `json:"product_id"`
+	ProductName string `json:"name"`
+	Slug        string `json:"slug"`
+	ImgURL      string `json:"img_url"`
+}
+
+// ComparisonRow holds one value per compared product, in the order of
+// Comparison.Products. Differs is set when the values are not all equal.
+type ComparisonRow struct {
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Unit    string `json:"unit,omitempty"`
+	Values  []any  `json:"values"`
+	Differs bool   `json:"differs"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+type Facets struct {
+	Total       int                     `json:"total"`
+	Categories  []FacetCount            `json:"categories"`
+	Brands      []FacetCount            `json:"brands"`
+	PriceRanges []FacetCount            `json:"price_ranges"`
+	Ratings     []FacetCount            `json:"ratings"`
+	InStock     int                     `json:"in_stock"`
+	HasOffer    int                     `json:"has_offer"`
+	Attributes  map[string][]FacetCount `json:"attributes"`
+}
+
+type Image struct {
+	ImageID      int    `json:"image_id"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	MediumURL    string `json:"medium_url"`
+	LargeURL     string `json:"large_url"`
+}
+
+type Breadcrumb struct {
+	CategoryID uint   `json:"category_id"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+}
+
+type CategoryNode struct {
+	CategoryID uint           `json:"category_id"`
+	Name       string         `json:"name"`
+	Slug       string         `json:"slug"`
+	Children   []CategoryNode `json:"children"`
+}
+
+type Variant struct {
+	VariantID int      `json:"variant_id"`
+	SKU       string   `json:"sku"`
+	Color     string   `json:"color,omitempty"`
+	Fabric    string   `json:"fabric,omitempty"`
+	Size      string   `json:"size,omitempty"`
+	Price     float64  `json:"price"`
+	Quantity  int      `json:"quantity"`
+	Images    []string `json:"images"`
 }
 
 type Address struct {
@@ -39,6 +206,8 @@ type Address struct {
 
 type CartResponse struct {
 	ProductID int `json:"product_id"`
+	VariantID int `json:"variant_id,omitempty"`
+	BundleID  int `json:"bundle_id,omitempty"`
 	Quantity  int `json:"quantity"`
 	Total     int `json:"total"`
 }
@@ -84,3 +253,14 @@ type Transaction struct {
 	Description     string    `json:"description" gorm:"type:varchar(255)"`
 	CreatedAt       time.Time `json:"created_at" gorm:"autoCreateTime"`
 }
+
+type Notification struct {
+	NotificationID uint      `json:"notification_id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	Body           string    `json:"body"`
+	OrderID        int       `json:"order_id,omitempty"`
+	ProductID      int       `json:"product_id,omitempty"`
+	Read           bool      `json:"read"`
+	CreatedAt      time.Time `json:"created_at"`
+}
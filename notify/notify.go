@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Event string
+
+const (
+	EventSignupOTP       Event = "signup_otp"
+	EventLoginOTP        Event = "login_otp"
+	EventOrderPlaced     Event = "order_placed"
+	EventPaymentCaptured Event = "payment_captured"
+	EventOrderShipped    Event = "order_shipped"
+	EventOrderDelivered  Event = "order_delivered"
+	EventItemCanceled    Event = "item_canceled"
+	EventReturnAccepted  Event = "return_accepted"
+	EventWalletRefunded  Event = "wallet_refunded"
+	EventBackInStock     Event = "back_in_stock"
+	EventPriceDrop       Event = "price_drop"
+)
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type Message struct {
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Notifier delivers a rendered message over a single channel (e-mail, SMS, ...).
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+var (
+	emailNotifier Notifier = &DisabledNotifier{Reason: "notify.Init has not run"}
+	smsNotifier   Notifier = &DisabledNotifier{Reason: "notify.Init has not run"}
+)
+
+// Init picks the e-mail and SMS channels from the environment. It must run
+// after the .env file has been loaded. The console driver has to be chosen
+// explicitly; a channel that is unknown or missing credentials is disabled
+// and refuses to send rather than print messages such as OTPs to the logs.
+func Init() {
+	policy := RetryPolicy{
+		MaxAttempts: envInt("NOTIFY_MAX_ATTEMPTS", 3),
+		Backoff:     time.Duration(envInt("NOTIFY_BACKOFF_MS", 500)) * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+	}
+	withRetry := func(n Notifier) Notifier {
+		if _, disabled := n.(*DisabledNotifier); disabled {
+			return n
+		}
+		return WithRetry(n, policy)
+	}
+
+	emailNotifier = withRetry(emailChannel())
+	smsNotifier = withRetry(smsChannel())
+}
+
+func emailChannel() Notifier {
+	switch driver := os.Getenv("NOTIFY_EMAIL_DRIVER"); driver {
+	case "console":
+		return NewWriterNotifier(os.Stdout)
+	case "file":
+		return NewFileNotifier(envString("NOTIFY_FILE_PATH", "notifications.log"))
+	case "", "smtp":
+		from := os.Getenv("EMAIL_ADDRESS")
+		password := os.Getenv("EMAIL_PASSWORD")
+		if from == "" || password == "" {
+			log.Error("Email credentials not set, e-mail notifications are disabled")
+			return &DisabledNotifier{Reason: "email credentials not set"}
+		}
+		return &SMTPNotifier{
+			Host:     envString("SMTP_HOST", "smtp.gmail.com"),
+			Port:     envInt("SMTP_PORT", 587),
+			Username: from,
+			Password: password,
+			From:     from,
+		}
+	default:
+		log.WithFields(log.Fields{"driver": driver}).Error("Unknown e-mail driver, e-mail notifications are disabled")
+		return &DisabledNotifier{Reason: "unknown e-mail driver " + driver}
+	}
+}
+
+func smsChannel() Notifier {
+	switch driver := os.Getenv("NOTIFY_SMS_DRIVER"); driver {
+	case "twilio":
+		provider := &TwilioProvider{
+			AccountSID:  os.Getenv("TWILIO_ACCOUNT_SID"),
+			AuthToken:   os.Getenv("TWILIO_AUTH_TOKEN"),
+			From:        os.Getenv("TWILIO_FROM"),
+			CountryCode: envString("SMS_COUNTRY_CODE", "+91"),
+		}
+		if provider.AccountSID == "" || provider.AuthToken == "" || provider.From == "" {
+			log.Error("Twilio credentials not set, SMS notifications are disabled")
+			return &DisabledNotifier{Reason: "twilio credentials not set"}
+		}
+		return &SMSNotifier{Provider: provider}
+	case "file":
+		return &SMSNotifier{Provider: &FileSMSProvider{Path: envString("NOTIFY_SMS_FILE_PATH", "sms_outbox.log")}}
+	case "console":
+		return NewWriterNotifier(os.Stdout)
+	case "":
+		log.Warn("No SMS driver set, SMS notifications are disabled")
+		return &DisabledNotifier{Reason: "no SMS driver set"}
+	default:
+		log.WithFields(log.Fields{"driver": driver}).Error("Unknown SMS driver, SMS notifications are disabled")
+		return &DisabledNotifier{Reason: "unknown SMS driver " + driver}
+	}
+}
+
+// Email renders the templates for event and delivers them in the background.
+// Failures are logged; they never reach the caller.
+func Email(to string, event Event, data any, attachments ...Attachment) {
+	msg, err := Render(event, data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event": event,
+			"error": err,
+		}).Error("cannot render notification")
+		return
+	}
+	msg.To = to
+	msg.Attachments = attachments
+
+	go deliver(emailNotifier, "email", event, msg)
+}
+
+// SMS renders the text template for event and delivers it in the background.
+func SMS(to string, event Event, data any) {
+	msg, err := Render(event, data)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"event": event,
+			"error": err,
+		}).Error("cannot render notification")
+		return
+	}
+	msg.To = to
+	msg.HTML = ""
+
+	go deliver(smsNotifier, "sms", event, msg)
+}
+
+func deliver(n Notifier, channel string, event Event, msg Message) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{
+				"channel": channel,
+				"event":   event,
+				"panic":   r,
+			}).Error("notification delivery panicked")
+		}
+	}()
+
+	if err := n.Send(context.Background(), msg); err != nil {
+		log.WithFields(log.Fields{
+			"channel": channel,
+			"event":   event,
+			"to":      msg.To,
+			"error":   err,
+		}).Error("notification delivery failed")
+	}
+}
+
+func envString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+type retryNotifier struct {
+	next   Notifier
+	policy RetryPolicy
+}
+
+// WithRetry wraps n so that failed sends are retried with exponential backoff.
+func WithRetry(n Notifier, policy RetryPolicy) Notifier {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	return &retryNotifier{next: n, policy: policy}
+}
+
+func (r *retryNotifier) Send(ctx context.Context, msg Message) error {
+	delay := r.policy.Backoff
+	var err error
+
+	for attempt := 1; attempt <= r.policy.MaxAttempts; attempt++ {
+		if err = r.next.Send(ctx, msg); err == nil {
+			return nil
+		}
+		if attempt == r.policy.MaxAttempts {
+			break
+		}
+
+		log.WithFields(log.Fields{
+			"to":      msg.To,
+			"attempt": attempt,
+			"retryIn": delay.String(),
+			"error":   err,
+		}).Warn("notification attempt failed")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if r.policy.MaxBackoff > 0 && delay > r.policy.MaxBackoff {
+			delay = r.policy.MaxBackoff
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", r.policy.MaxAttempts, err)
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WriterNotifier prints messages instead of delivering them. It is meant for
+// local development, where the OTP or order mail only needs to be readable.
+type WriterNotifier struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterNotifier(w io.Writer) *WriterNotifier {
+	return &WriterNotifier{w: w}
+}
+
+func (n *WriterNotifier) Send(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := io.WriteString(n.w, formatMessage(msg))
+	return err
+}
+
+// DisabledNotifier refuses every message. It stands in for a channel that is
+// not configured, so messages (OTPs included) never end up on the console.
+type DisabledNotifier struct {
+	Reason string
+}
+
+func (n *DisabledNotifier) Send(ctx context.Context, msg Message) error {
+	return fmt.Errorf("channel disabled: %s", n.Reason)
+}
+
+// FileNotifier appends every message to a file on disk.
+type FileNotifier struct {
+	mu   sync.Mutex
+	Path string
+}
+
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{Path: path}
+}
+
+func (n *FileNotifier) Send(ctx context.Context, msg Message) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open notification file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.WriteString(f, formatMessage(msg)); err != nil {
+		return fmt.Errorf("cannot write notification: %w", err)
+	}
+	return nil
+}
+
+func formatMessage(msg Message) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "---- %s ----\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&b, "To: %s\n", msg.To)
+	if msg.Subject != "" {
+		fmt.Fprintf(&b, "Subject: %s\n", msg.Subject)
+	}
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "Attachment: %s (%s, %d bytes)\n", a.Filename, a.ContentType, len(a.Data))
+	}
+	b.WriteString("\n")
+	b.WriteString(msg.Text)
+	b.WriteString("\n\n")
+	return b.String()
+}
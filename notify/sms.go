@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SMSProvider is the gateway-specific part of sending a text message.
+type SMSProvider interface {
+	SendSMS(ctx context.Context, to, body string) error
+}
+
+// SMSNotifier adapts an SMSProvider to the Notifier interface. Only the plain
+// text body of the message is sent.
+type SMSNotifier struct {
+	Provider SMSProvider
+}
+
+func (n *SMSNotifier) Send(ctx context.Context, msg Message) error {
+	return n.Provider.SendSMS(ctx, msg.To, msg.Text)
+}
+
+// FileSMSProvider appends each text message to a file instead of sending it,
+// so phone OTP flows can be exercised locally.
+type FileSMSProvider struct {
+	mu   sync.Mutex
+	Path string
+}
+
+func (p *FileSMSProvider) SendSMS(ctx context.Context, to, body string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open sms outbox: %w", err)
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), to, strings.ReplaceAll(body, "\n", " "))
+	return err
+}
+
+type TwilioProvider struct {
+	AccountSID  string
+	AuthToken   string
+	From        string
+	CountryCode string
+}
+
+var smsHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+func (p *TwilioProvider) SendSMS(ctx context.Context, to, body string) error {
+	if p.AccountSID == "" || p.AuthToken == "" || p.From == "" {
+		return fmt.Errorf("twilio credentials not set")
+	}
+
+	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", p.AccountSID)
+	form := url.Values{}
+	if !strings.HasPrefix(to, "+") {
+		to = p.CountryCode + to
+	}
+	form.Set("To", to)
+	form.Set("From", p.From)
+	form.Set("Body", body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(p.AccountSID, p.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := smsHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sms provider returned %s", resp.Status)
+	}
+	return nil
+}
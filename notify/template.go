@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+)
+
+var subjects = map[Event]string{
+	EventSignupOTP:       "Your verification code for The Furnish Store",
+	EventLoginOTP:        "Your login code for The Furnish Store",
+	EventOrderPlaced:     "Order #{{.OrderID}} placed",
+	EventPaymentCaptured: "Payment received for order #{{.OrderID}}",
+	EventOrderShipped:    "Order #{{.OrderID}} has shipped",
+	EventOrderDelivered:  "Order #{{.OrderID}} delivered",
+	EventItemCanceled:    "Item canceled from order #{{.OrderID}}",
+	EventReturnAccepted:  "Return accepted for order #{{.OrderID}}",
+	EventWalletRefunded:  "Refund credited to your wallet",
+	EventBackInStock:     "{{.ProductName}} is back in stock",
+	EventPriceDrop:       "Price drop on {{.ProductName}}",
+}
+
+// inboxBodies are the short texts shown in the in-app notification inbox.
+// Events without an entry are e-mail only.
+var inboxBodies = map[Event]string{
+	EventOrderShipped:   "Your order #{{.OrderID}} is on its way.",
+	EventOrderDelivered: "Your order #{{.OrderID}} has been delivered.",
+	EventItemCanceled:   "Product #{{.ProductID}} was canceled from order #{{.OrderID}}.",
+	EventReturnAccepted: "Your return for order #{{.OrderID}} has been accepted.",
+	EventWalletRefunded: `{{printf "%.2f" .Amount}} was credited to your wallet for order #{{.OrderID}}.`,
+	EventBackInStock:    "{{.ProductName}} from your wishlist is available again.",
+	EventPriceDrop:      `{{.ProductName}} from your wishlist dropped from {{printf "%.2f" .OldPrice}} to {{printf "%.2f" .NewPrice}}.`,
+}
+
+// RenderInbox builds the title and body of the in-app notification for event.
+// ok is false when the event is not shown in the inbox.
+func RenderInbox(event Event, data any) (title, body string, ok bool, err error) {
+	bodyText, ok := inboxBodies[event]
+	if !ok {
+		return "", "", false, nil
+	}
+
+	if title, err = renderString(subjects[event], data); err != nil {
+		return "", "", true, err
+	}
+	if body, err = renderString(bodyText, data); err != nil {
+		return "", "", true, err
+	}
+	return title, body, true, nil
+}
+
+func renderString(text string, data any) (string, error) {
+	tmpl, err := texttemplate.New("inline").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Render builds the subject, text and HTML bodies for event. Every event needs
+// a subject and a .txt template; the .html template is optional.
+func Render(event Event, data any) (Message, error) {
+	var msg Message
+
+	subject, ok := subjects[event]
+	if !ok {
+		return msg, fmt.Errorf("unknown notification event %q", event)
+	}
+	var err error
+	if msg.Subject, err = renderString(subject, data); err != nil {
+		return msg, fmt.Errorf("cannot render subject: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := textTemplates.ExecuteTemplate(&buf, string(event)+".txt", data); err != nil {
+		return msg, fmt.Errorf("cannot render text body: %w", err)
+	}
+	msg.Text = strings.TrimSpace(buf.String())
+
+	if t := htmlTemplates.Lookup(string(event) + ".html"); t != nil {
+		buf.Reset()
+		if err := t.Execute(&buf, data); err != nil {
+			return msg, fmt.Errorf("cannot render html body: %w", err)
+		}
+		msg.HTML = buf.String()
+	}
+
+	return msg, nil
+}
@@ -3,20 +3,27 @@ package route
 import (
 	"admin/admin"
 	adminuser "admin/admin/adminUser"
+	"admin/admin/brand"
 	"admin/admin/category"
 	"admin/admin/coupon"
 	"admin/admin/offer"
 	"admin/admin/order"
 	"admin/admin/product"
+	"admin/admin/question"
 	salesreport "admin/admin/salesReport"
 
 	"admin/middleware"
+	"admin/storage"
 	"admin/user"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterURL(router *gin.Engine) {
+	if dir := storage.LocalDir(); dir != "" {
+		router.Static("/uploads", dir)
+	}
+
 	//User
 	router.POST("/signup", user.SignUp)
 	router.GET("/googlelogin", user.HandleGoogleLogin)
@@ -24,11 +31,30 @@ func RegisterURL(router *gin.Engine) {
 	router.POST("/verifyotp", user.VerifyOTP)
 	router.POST("/resendotp/:email", user.ResendOTP)
 	router.POST("/login", user.Login)
+	router.POST("/login/otp/request", user.RequestLoginOTP)
+	router.POST("/login/otp/verify", user.LoginWithOTP)
 	router.PUT("/forgotpassword", middleware.AuthMiddleware("user"), user.ForgotPassword)
 
 	//Products
 	router.GET("/products", user.ViewProducts)
 	router.GET("/search-products", user.SearchProducts)
+	router.GET("/search/suggestions", user.SearchSuggestions)
+	router.GET("/products/compare", user.CompareProducts)
+	router.GET("/products/:id", middleware.OptionalAuth("user"), user.ProductDetail)
+	router.GET("/products/slug/:slug", middleware.OptionalAuth("user"), user.ProductBySlug)
+	router.GET("/products/:id/related", user.RelatedProducts)
+	router.GET("/recently-viewed", middleware.OptionalAuth("user"), user.RecentlyViewed)
+	router.GET("/products/:id/questions", user.ListQuestions)
+	router.GET("/bundles", user.ListBundles)
+	router.GET("/brands", user.ListBrands)
+	router.GET("/brands/:id", user.BrandDetail)
+	router.GET("/bundles/:id", user.BundleDetail)
+	router.POST("/products/:id/questions", middleware.AuthMiddleware("user"), user.AskQuestion)
+	router.POST("/questions/:id/answers", middleware.AuthMiddleware("user"), user.AnswerQuestion)
+	router.POST("/answers/:id/upvote", middleware.AuthMiddleware("user"), user.UpvoteAnswer)
+	router.DELETE("/answers/:id/upvote", middleware.AuthMiddleware("user"), user.RemoveAnswerVote)
+	router.GET("/categories/tree", user.CategoryTree)
+	router.GET("/categories/slug/:slug", user.CategoryBySlug)
 
 	//Profile
 	router.GET("/viewprofile", middleware.AuthMiddleware("user"), user.UserProfile)
@@ -40,24 +66,36 @@ func RegisterURL(router *gin.Engine) {
 	router.GET("/user/wallet", middleware.AuthMiddleware("user"), user.ViewWallet)
 	router.GET("/wallet/transactions", middleware.AuthMiddleware("user"), user.GetWalletTransactions)
 
+	//Notifications
+	router.GET("/user/notifications", middleware.AuthMiddleware("user"), user.ListNotifications)
+	router.GET("/user/notifications/unread-count", middleware.AuthMiddleware("user"), user.UnreadNotificationCount)
+	router.PUT("/user/notifications/:id/read", middleware.AuthMiddleware("user"), user.MarkNotificationRead)
+	router.PUT("/user/notifications/read-all", middleware.AuthMiddleware("user"), user.MarkAllNotificationsRead)
+
 	//Orders
 	router.GET("/vieworders", middleware.AuthMiddleware("user"), user.ViewOrders)
 	router.DELETE("/orders/:id/delete", middleware.AuthMiddleware("user"), user.CancelOrders)
 	router.POST("/users/order", middleware.AuthMiddleware("user"), user.Orders)
 	router.GET("/paypal/confirmpayment", user.CapturePayPalOrder)
-	router.GET("/paypal/cancel-payment", user.CapturePayPalOrder)
+	router.GET("/paypal/cancel-payment", user.CancelPayPalOrder)
 	router.POST("/user/returnorder", middleware.AuthMiddleware("user"), user.ReturnOrder)
 	router.POST("/user/generate-invoice/:id", middleware.AuthMiddleware("user"), user.GenerateInvoiceHandler)
 	//Cart
 	router.GET("/user/cart", middleware.AuthMiddleware("user"), user.Cart)
 	router.POST("/user/addtocart", middleware.AuthMiddleware("user"), user.AddToCart)
 	router.DELETE("user/removeitem/:id", middleware.AuthMiddleware("user"), user.RemoveItem)
+	router.POST("/user/cart/bundle", middleware.AuthMiddleware("user"), user.AddBundleToCart)
+	router.DELETE("/user/cart/bundle/:id", middleware.AuthMiddleware("user"), user.RemoveBundleFromCart)
 
 	//Whishlist
 	router.GET("/user/viewwhishlist", middleware.AuthMiddleware("user"), user.ViewWhishlist)
 	router.POST("/user/addtowhishlist", middleware.AuthMiddleware("user"), user.AddToWhishlist)
 	router.DELETE("/user/removeitem", middleware.AuthMiddleware("user"), user.WishlistRemoveItem)
 	router.DELETE("/user/clearwishlist", middleware.AuthMiddleware("user"), user.ClearWishlist)
+	router.GET("/user/compare", middleware.AuthMiddleware("user"), user.ViewCompareList)
+	router.POST("/user/compare", middleware.AuthMiddleware("user"), user.AddToCompareList)
+	router.DELETE("/user/compare/:id", middleware.AuthMiddleware("user"), user.RemoveFromCompareList)
+	router.DELETE("/user/compare", middleware.AuthMiddleware("user"), user.ClearCompareList)
 
 	//Coupons
 	router.GET("/coupons", middleware.AuthMiddleware("user"), user.ViewCoupons)
@@ -73,12 +111,47 @@ func RegisterURL(router *gin.Engine) {
 	router.POST("/addcategory", middleware.AuthMiddleware("admin"), category.AddCategory)
 	router.PUT("/updatecategory/:id", middleware.AuthMiddleware("admin"), category.EditCategory)
 	router.DELETE("/deletecategory/:id", middleware.AuthMiddleware("admin"), category.DeleteCategory)
+	router.GET("/admin/trash/categories", middleware.AuthMiddleware("admin"), category.ListTrashedCategories)
+	router.POST("/admin/categories/:id/restore", middleware.AuthMiddleware("admin"), category.RestoreCategory)
+	router.GET("/admin/categories/:id/attributes", middleware.AuthMiddleware("admin"), category.ListAttributes)
+	router.POST("/admin/categories/:id/attributes", middleware.AuthMiddleware("admin"), category.AddAttribute)
+	router.PUT("/admin/attributes/:id", middleware.AuthMiddleware("admin"), category.UpdateAttribute)
+	router.DELETE("/admin/attributes/:id", middleware.AuthMiddleware("admin"), category.DeleteAttribute)
+
+	router.GET("/admin/questions", middleware.AuthMiddleware("admin"), question.ListQuestions)
+	router.POST("/admin/questions/:id/answers", middleware.AuthMiddleware("admin"), question.AnswerQuestion)
+	router.PUT("/admin/questions/:id/moderate", middleware.AuthMiddleware("admin"), question.ModerateQuestion)
+	router.PUT("/admin/answers/:id/moderate", middleware.AuthMiddleware("admin"), question.ModerateAnswer)
 
 	router.GET("/viewproducts", middleware.AuthMiddleware("admin"), product.ViewProducts)
 	router.POST("/addproducts", middleware.AuthMiddleware("admin"), product.AddProducts)
 	router.PUT("/updateproduct/:id", middleware.AuthMiddleware("admin"), product.UpdateProduct)
 	router.DELETE("/deleteproduct/:id", middleware.AuthMiddleware("admin"), product.DeleteProduct)
+	router.GET("/admin/brands", middleware.AuthMiddleware("admin"), brand.ListBrands)
+	router.POST("/admin/brands", middleware.AuthMiddleware("admin"), brand.AddBrand)
+	router.PUT("/admin/brands/:id", middleware.AuthMiddleware("admin"), brand.UpdateBrand)
+	router.DELETE("/admin/brands/:id", middleware.AuthMiddleware("admin"), brand.DeleteBrand)
+	router.GET("/admin/bundles", middleware.AuthMiddleware("admin"), product.ListBundles)
+	router.POST("/admin/bundles", middleware.AuthMiddleware("admin"), product.AddBundle)
+	router.PUT("/admin/bundles/:id", middleware.AuthMiddleware("admin"), product.UpdateBundle)
+	router.DELETE("/admin/bundles/:id", middleware.AuthMiddleware("admin"), product.DeleteBundle)
+	router.GET("/admin/trash/products", middleware.AuthMiddleware("admin"), product.ListTrashedProducts)
+	router.POST("/admin/products/:id/restore", middleware.AuthMiddleware("admin"), product.RestoreProduct)
+	router.DELETE("/admin/products/:id/purge", middleware.AuthMiddleware("admin"), product.PurgeProduct)
+	router.POST("/admin/products/import", middleware.AuthMiddleware("admin"), product.ImportProducts)
+	router.GET("/admin/products/export", middleware.AuthMiddleware("admin"), product.ExportProducts)
+	router.PUT("/admin/products/:id/lifecycle", middleware.AuthMiddleware("admin"), product.UpdateProductLifecycle)
+	router.GET("/admin/products/:id/price-history", middleware.AuthMiddleware("admin"), product.PriceHistory)
+	router.GET("/admin/products/:id/stock-movements", middleware.AuthMiddleware("admin"), product.StockMovements)
 	router.PUT("/admin/updatestock/:id", middleware.AuthMiddleware("admin"), product.UpdateProductStock)
+	router.GET("/admin/products/:id/variants", middleware.AuthMiddleware("admin"), product.ListVariants)
+	router.POST("/admin/products/:id/variants", middleware.AuthMiddleware("admin"), product.AddVariant)
+	router.PUT("/admin/variants/:id", middleware.AuthMiddleware("admin"), product.UpdateVariant)
+	router.DELETE("/admin/variants/:id", middleware.AuthMiddleware("admin"), product.DeleteVariant)
+	router.GET("/admin/products/:id/images", middleware.AuthMiddleware("admin"), product.ListImages)
+	router.POST("/admin/products/:id/images", middleware.AuthMiddleware("admin"), product.UploadImages)
+	router.PUT("/admin/products/:id/images/order", middleware.AuthMiddleware("admin"), product.ReorderImages)
+	router.DELETE("/admin/images/:id", middleware.AuthMiddleware("admin"), product.DeleteImage)
 
 	router.GET("/listusers", middleware.AuthMiddleware("admin"), adminuser.ListUsers)
 	router.POST("blockuser/:id", middleware.AuthMiddleware("admin"), adminuser.BlockUser)
@@ -99,6 +172,7 @@ func RegisterURL(router *gin.Engine) {
 	router.GET("/get-sales-data", middleware.AuthMiddleware("admin"), salesreport.GetSalesData)
 	router.GET("/top-selling-product", middleware.AuthMiddleware("admin"), salesreport.GetTopSellingProducts)
 	router.GET("/top-selling-category", middleware.AuthMiddleware("admin"), salesreport.GetTopSellingCategories)
+	router.GET("/top-selling-brand", middleware.AuthMiddleware("admin"), salesreport.GetTopSellingBrands)
 	router.GET("/ledger-book", middleware.AuthMiddleware("admin"), salesreport.GetLedgerBook)
 
 }
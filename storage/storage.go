@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Storage keeps uploaded files and returns the public URL they are served from.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var Default Storage = &LocalStorage{Dir: "uploads", BaseURL: "/uploads"}
+
+// Init picks the storage backend from the environment. It must run after the
+// .env file has been loaded.
+func Init() {
+	switch os.Getenv("STORAGE_DRIVER") {
+	case "s3":
+		s3 := &S3Storage{
+			Endpoint:  strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/"),
+			Region:    envString("S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: strings.TrimSuffix(os.Getenv("S3_PUBLIC_URL"), "/"),
+			PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		}
+		if s3.Bucket == "" || s3.AccessKey == "" || s3.SecretKey == "" {
+			log.Warn("S3 storage is not fully configured, falling back to local disk")
+			break
+		}
+		Default = s3
+		return
+	}
+
+	Default = &LocalStorage{
+		Dir:     envString("UPLOAD_DIR", "uploads"),
+		BaseURL: strings.TrimSuffix(envString("UPLOAD_BASE_URL", "/uploads"), "/"),
+	}
+}
+
+// LocalDir returns the directory served under /uploads when files are kept on
+// local disk, or "" when another backend is in use.
+func LocalDir() string {
+	if local, ok := Default.(*LocalStorage); ok {
+		return local.Dir
+	}
+	return ""
+}
+
+func envString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
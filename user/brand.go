@@ -0,0 +1,82 @@
+package user
+
+import (
+	"net/http"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ListBrands returns the brands that have at least one published product.
+func ListBrands(c *gin.Context) {
+	var brands []struct {
+		models.Brand
+		Products int `json:"products"`
+	}
+	if err := db.Db.Model(&models.Product{}).Scopes(helper.Published).
+		Select("brands.*, COUNT(*) AS products").
+		Joins("JOIN brands ON brands.brand_id = products.brand_id AND brands.deleted_at IS NULL").
+		Group("brands.brand_id").
+		Order("brands.name ASC").
+		Scan(&brands).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching brands"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"brands": brands})
+}
+
+// BrandDetail is the brand landing page: the brand and its published
+// products. The usual product filters apply, so the page can be faceted
+// like a search.
+func BrandDetail(c *gin.Context) {
+	var brand models.Brand
+	if err := db.Db.Where("brand_id = ?", c.Param("id")).First(&brand).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+		return
+	}
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Brands = []uint{brand.BrandID}
+
+	query := filter.apply(db.Db.Model(&models.Product{}).Scopes(helper.Published)).Session(&gorm.Session{})
+
+	facets, err := productFacets(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching products"})
+		return
+	}
+
+	page, limit, offset := helper.Paginate(c)
+	var rows []productRow
+	if err := query.Select(productColumns).
+		Order("products.popularity DESC, products.product_id ASC").
+		Limit(limit).Offset(offset).
+		Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching products"})
+		return
+	}
+
+	products, err := buildProductResponses(rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"brand":    brand,
+		"products": products,
+		"facets":   facets,
+		"page":     page,
+		"limit":    limit,
+		"total":    facets.Total,
+	})
+}
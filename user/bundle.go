@@ -0,0 +1,151 @@
+package user
+
+import (
+	"net/http"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func ListBundles(c *gin.Context) {
+	var bundles []models.Bundle
+	if err := db.Db.Preload("Components").Order("bundle_id ASC").Find(&bundles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch bundles"})
+		return
+	}
+
+	response := make([]responsemodels.Bundle, 0, len(bundles))
+	for _, bundle := range bundles {
+		details, _, err := helper.BundleDetails(db.Db, bundle)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"BundleID": bundle.BundleID,
+				"error":    err,
+			}).Warn("Skipping bundle with missing components")
+			continue
+		}
+		response = append(response, details)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bundles": response})
+}
+
+func BundleDetail(c *gin.Context) {
+	var bundle models.Bundle
+	if err := db.Db.Preload("Components").Where("bundle_id = ?", c.Param("id")).First(&bundle).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found"})
+		return
+	}
+
+	details, _, err := helper.BundleDetails(db.Db, bundle)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle is no longer available"})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// AddBundleToCart expands a bundle into one cart line per component, tagged
+// with the bundle ID and priced at the component's share of the bundle price.
+func AddBundleToCart(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	var input models.BundleCartInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	cartLock.Lock()
+	defer cartLock.Unlock()
+
+	var bundle models.Bundle
+	if err := db.Db.Preload("Components").First(&bundle, input.BundleID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found"})
+		return
+	}
+
+	details, shares, err := helper.BundleDetails(db.Db, bundle)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle is no longer available"})
+		return
+	}
+
+	var lines []models.Cart
+	db.Db.Where("user_id = ? AND bundle_id = ?", userID, bundle.BundleID).Find(&lines)
+	inCart := 0
+	if len(lines) > 0 && len(bundle.Components) > 0 {
+		for _, line := range lines {
+			if line.ProductID == bundle.Components[0].ProductID && line.VariantID == bundle.Components[0].VariantID {
+				inCart = line.Quantity / bundle.Components[0].Quantity
+			}
+		}
+	}
+
+	quantity := inCart + input.Quantity
+	if quantity > MaxQuantity {
+		c.JSON(http.StatusOK, gin.H{"message": "Quantity limit exceeded"})
+		return
+	}
+	if quantity > details.Stock {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "There is no sufficient quantity"})
+		return
+	}
+
+	err = db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND bundle_id = ?", userID, bundle.BundleID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		for i, component := range bundle.Components {
+			line := models.Cart{
+				UserID:    int(userID),
+				ProductID: component.ProductID,
+				VariantID: component.VariantID,
+				BundleID:  bundle.BundleID,
+				Quantity:  component.Quantity * quantity,
+				Total:     shares[i] * quantity,
+			}
+			if err := tx.Create(&line).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"UserID":   userID,
+			"BundleID": bundle.BundleID,
+			"error":    err,
+		}).Error("Cannot add bundle to cart")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error adding bundle to cart"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle added to cart", "bundle_id": bundle.BundleID, "quantity": quantity})
+}
+
+func RemoveBundleFromCart(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	result := db.Db.Where("user_id = ? AND bundle_id = ?", userID, c.Param("id")).Delete(&models.Cart{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove bundle from cart"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found in cart"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bundle removed successfully"})
+}
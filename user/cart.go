@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	db "admin/DB"
+	"admin/helper"
 	"admin/middleware"
 	"admin/models"
 	"admin/models/responsemodels"
@@ -32,7 +33,7 @@ func Cart(c *gin.Context) {
 	var cartItems []responsemodels.CartResponse
 
 	if err := db.Db.Table("carts").
-		Select("carts.product_id, carts.quantity, carts.total").
+		Select("carts.product_id, carts.variant_id, carts.bundle_id, carts.quantity, carts.total").
 		Joins("join users on users.id = carts.user_id").
 		Where("carts.user_id = ?", userID).
 		Scan(&cartItems).Error; err != nil {
@@ -71,11 +72,31 @@ func AddToCart(c *gin.Context) {
 	var cartItem models.Cart
 	var product models.Product
 
-	if err := db.Db.First(&product, item.ProductID).Error; err != nil {
+	if err := db.Db.Scopes(helper.Published).First(&product, item.ProductID).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
-	if item.Quantity > product.Quantity {
+
+	var hasVariants int64
+	db.Db.Model(&models.ProductVariant{}).Where("product_id = ?", product.ProductID).Count(&hasVariants)
+	if hasVariants != 0 && item.VariantID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Please choose a variant"})
+		return
+	}
+
+	available := product.Quantity
+	unitPrice := helper.UnitPrice(product, nil)
+	if item.VariantID != 0 {
+		variant, err := helper.FindVariant(db.Db, product.ProductID, item.VariantID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+			return
+		}
+		available = variant.Quantity
+		unitPrice = helper.UnitPrice(product, &variant)
+	}
+
+	if item.Quantity > available {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "There is no sufficient quantity"})
 		return
 	}
@@ -85,9 +106,9 @@ func AddToCart(c *gin.Context) {
 		return
 	}
 
-	if err := db.Db.Where("user_id = ? AND product_id = ?", userID, item.ProductID).First(&cartItem).Error; err == nil {
+	if err := db.Db.Where("user_id = ? AND product_id = ? AND variant_id = ? AND bundle_id = 0", userID, item.ProductID, item.VariantID).First(&cartItem).Error; err == nil {
 		cartItem.Quantity += item.Quantity
-		cartItem.Total = cartItem.Quantity * int(product.Price)
+		cartItem.Total = cartItem.Quantity * int(unitPrice)
 		if err := db.Db.Save(&cartItem).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error updating cart item"})
 			return
@@ -100,8 +121,9 @@ func AddToCart(c *gin.Context) {
 		newCartItem := models.Cart{
 			UserID:    int(userID),
 			ProductID: item.ProductID,
+			VariantID: item.VariantID,
 			Quantity:  item.Quantity,
-			Total:     item.Quantity * int(product.Price),
+			Total:     item.Quantity * int(unitPrice),
 		}
 		if err := db.Db.Create(&newCartItem).Error; err != nil {
 			fmt.Println(err)
@@ -132,7 +154,12 @@ func RemoveItem(c *gin.Context) {
 
 	var cart models.Cart
 
-	if err := db.Db.Where("user_id =? AND product_id =?", userID, ProductID).First(&cart).Error; err != nil {
+	// Bundle components are removed together through RemoveBundleFromCart.
+	query := db.Db.Where("user_id =? AND product_id =? AND bundle_id = 0", userID, ProductID)
+	if variantID := c.Query("variant_id"); variantID != "" {
+		query = query.Where("variant_id = ?", variantID)
+	}
+	if err := query.First(&cart).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found in cart"})
 			return
@@ -140,17 +167,6 @@ func RemoveItem(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
-	var product models.Product
-	if err := db.Db.First(&product, cart.ProductID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find product"})
-		return
-	}
-
-	product.Quantity += cart.Quantity
-	if err := db.Db.Save(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
-		return
-	}
 	if err := db.Db.Delete(&cart).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove item from cart"})
 		return
@@ -0,0 +1,19 @@
+package user
+
+import (
+	"net/http"
+
+	"admin/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CategoryTree(c *gin.Context) {
+	index, err := helper.LoadCategoryIndex()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"categories": index.Tree()})
+}
@@ -0,0 +1,282 @@
+package user
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MinCompare = 2
+	MaxCompare = 4
+)
+
+// CompareProducts lines up 2 to 4 published products, given as
+// ?ids=1,2,3, attribute by attribute. With ?only_differences=true rows
+// where every product has the same value are left out.
+func CompareProducts(c *gin.Context) {
+	var ids []int
+	seen := map[int]bool{}
+	for _, value := range strings.Split(c.Query("ids"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product id " + value})
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) < MinCompare || len(ids) > MaxCompare {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Compare between %d and %d products", MinCompare, MaxCompare)})
+		return
+	}
+
+	comparison, missing, err := compareProducts(ids, c.Query("only_differences") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error comparing products"})
+		return
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Products not found", "product_ids": missing})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// ViewCompareList returns the user's saved comparison list as a matrix.
+// Products that are no longer published are dropped from the list.
+func ViewCompareList(c *gin.Context) {
+	userID := middleware.CurrentID(c)
+
+	var ids []int
+	if err := db.Db.Model(&models.CompareItem{}).Where("user_id = ?", userID).
+		Order("created_at ASC").Pluck("product_id", &ids).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch comparison list"})
+		return
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Comparison list is empty"})
+		return
+	}
+
+	comparison, missing, err := compareProducts(ids, c.Query("only_differences") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error comparing products"})
+		return
+	}
+	if len(missing) > 0 {
+		db.Db.Where("user_id = ? AND product_id IN ?", userID, missing).Delete(&models.CompareItem{})
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+func AddToCompareList(c *gin.Context) {
+	userID := middleware.CurrentID(c)
+
+	var input struct {
+		ProductID int `json:"product_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var product models.Product
+	if err := db.Db.Scopes(helper.Published).First(&product, input.ProductID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var count int64
+	db.Db.Model(&models.CompareItem{}).Where("user_id = ? AND product_id <> ?", userID, product.ProductID).Count(&count)
+	if count >= MaxCompare {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("You can compare up to %d products, remove one first", MaxCompare)})
+		return
+	}
+
+	item := models.CompareItem{UserID: userID, ProductID: product.ProductID}
+	if err := db.Db.Clauses(clause.OnConflict{DoNothing: true}).Create(&item).Error; err != nil {
+		log.WithFields(log.Fields{
+			"UserID":    userID,
+			"ProductID": product.ProductID,
+			"error":     err,
+		}).Error("Cannot add to comparison list")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot add to comparison list"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Product added to comparison list"})
+}
+
+func RemoveFromCompareList(c *gin.Context) {
+	userID := middleware.CurrentID(c)
+
+	result := db.Db.Where("user_id = ? AND product_id = ?", userID, c.Param("id")).Delete(&models.CompareItem{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot update comparison list"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product is not in the comparison list"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product removed from comparison list"})
+}
+
+func ClearCompareList(c *gin.Context) {
+	if err := db.Db.Where("user_id = ?", middleware.CurrentID(c)).Delete(&models.CompareItem{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot clear comparison list"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comparison list cleared"})
+}
+
+// compareProducts builds the matrix for the published products among ids,
+// keeping their order, and reports the ids that were not found.
+func compareProducts(ids []int, onlyDifferences bool) (responsemodels.Comparison, []int, error) {
+	var rows []productRow
+	if err := db.Db.Model(&models.Product{}).Scopes(helper.Published).
+		Select(productColumns).
+		Where("products.product_id IN ?", ids).
+		Find(&rows).Error; err != nil {
+		return responsemodels.Comparison{}, nil, err
+	}
+
+	found, err := buildProductResponses(rows)
+	if err != nil {
+		return responsemodels.Comparison{}, nil, err
+	}
+	byID := map[int]responsemodels.Products{}
+	for _, product := range found {
+		byID[product.ProductID] = product
+	}
+
+	var products []responsemodels.Products
+	var missing []int
+	for _, id := range ids {
+		if product, ok := byID[id]; ok {
+			products = append(products, product)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+
+	comparison := responsemodels.Comparison{
+		Products: make([]responsemodels.ComparedProduct, len(products)),
+		Rows:     []responsemodels.ComparisonRow{},
+	}
+	for i, product := range products {
+		comparison.Products[i] = responsemodels.ComparedProduct{
+			ProductID:   product.ProductID,
+			ProductName: product.ProductName,
+			Slug:        product.Slug,
+			ImgURL:      product.ImgURL,
+		}
+	}
+
+	addRow := func(key, label, unit string, value func(responsemodels.Products) any) {
+		row := responsemodels.ComparisonRow{Key: key, Label: label, Unit: unit, Values: make([]any, len(products))}
+		for i, product := range products {
+			row.Values[i] = value(product)
+			if i > 0 && !reflect.DeepEqual(row.Values[i], row.Values[0]) {
+				row.Differs = true
+			}
+		}
+		if row.Differs || !onlyDifferences {
+			comparison.Rows = append(comparison.Rows, row)
+		}
+	}
+
+	addRow("price", "Price", "", func(p responsemodels.Products) any { return p.Price })
+	addRow("final_price", "Price after offer", "", func(p responsemodels.Products) any {
+		return p.Price * (100 - p.OfferDiscount) / 100
+	})
+	addRow("offer", "Offer", "%", func(p responsemodels.Products) any { return p.OfferDiscount })
+	addRow("rating", "Rating", "", func(p responsemodels.Products) any { return p.AverageRating })
+	addRow("reviews", "Reviews", "", func(p responsemodels.Products) any { return p.TotalReviews })
+	addRow("brand", "Brand", "", func(p responsemodels.Products) any { return p.BrandName })
+	addRow("category", "Category", "", func(p responsemodels.Products) any {
+		if len(p.Breadcrumbs) == 0 {
+			return ""
+		}
+		return p.Breadcrumbs[len(p.Breadcrumbs)-1].Name
+	})
+	addRow("stock", "Stock", "", func(p responsemodels.Products) any { return p.Status })
+	addRow("quantity", "Quantity available", "", func(p responsemodels.Products) any { return p.Quantity })
+
+	// Structured attributes in the order they first appear, so products from
+	// different categories still line up on the attributes they share.
+	var attributes []responsemodels.Attribute
+	known := map[string]bool{}
+	for _, product := range products {
+		for _, attribute := range product.Attributes {
+			if !known[attribute.Name] {
+				known[attribute.Name] = true
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	for _, attribute := range attributes {
+		name := attribute.Name
+		addRow("attr."+name, attribute.Label, attribute.Unit, func(p responsemodels.Products) any {
+			for _, value := range p.Attributes {
+				if value.Name == name {
+					return value.Value
+				}
+			}
+			return nil
+		})
+	}
+
+	for _, option := range variantAttributes {
+		addRow("variant."+option, strings.ToUpper(option[:1])+option[1:], "", func(p responsemodels.Products) any {
+			return variantOptions(p.Variants, option)
+		})
+	}
+
+	return comparison, missing, nil
+}
+
+func variantOptions(variants []responsemodels.Variant, option string) []string {
+	values := []string{}
+	seen := map[string]bool{}
+	for _, variant := range variants {
+		var value string
+		switch option {
+		case "color":
+			value = variant.Color
+		case "fabric":
+			value = variant.Fabric
+		case "size":
+			value = variant.Size
+		}
+		if value != "" && !seen[strings.ToLower(value)] {
+			seen[strings.ToLower(value)] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
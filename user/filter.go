@@ -0,0 +1,357 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	ratingExpr    = `(SELECT COALESCE(AVG(r.rating), 0) FROM review_ratings r WHERE r.product_id = products.product_id)`
+	reviewsExpr   = `(SELECT COUNT(*) FROM review_ratings r WHERE r.product_id = products.product_id)`
+	inStockExpr   = `(products.quantity > 0 OR EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.product_id AND v.quantity > 0 AND v.deleted_at IS NULL))`
+	brandNameExpr = `(SELECT b.name FROM brands b WHERE b.brand_id = products.brand_id AND b.deleted_at IS NULL)`
+	hasOfferExpr  = `EXISTS (SELECT 1 FROM offers o WHERE o.product_id = products.product_id AND o.offer_percentage > 0 AND o.deleted_at IS NULL)`
+
+	questionsExpr = `(SELECT COUNT(*) FROM product_questions q WHERE q.product_id = products.product_id AND q.status = 'visible')`
+	answeredExpr  = `(SELECT COUNT(*) FROM product_questions q WHERE q.product_id = products.product_id AND q.status = 'visible'
+		AND EXISTS (SELECT 1 FROM product_answers a WHERE a.question_id = q.question_id AND a.status = 'visible'))`
+)
+
+// variantAttributes are the variant columns shoppers can filter on.
+var variantAttributes = []string{"color", "fabric", "size"}
+
+// priceBreaks split the price facet into buckets; the last one is open-ended.
+var priceBreaks = []float64{1000, 5000, 10000, 25000, 50000}
+
+type productFilter struct {
+	MinPrice   *float64
+	MaxPrice   *float64
+	MinRating  float64
+	InStock    bool
+	HasOffer   bool
+	Brands     []uint
+	Attributes map[string][]string
+	Specs      []specFilter
+}
+
+// specFilter narrows results by a structured product attribute, passed as
+// attr[name]=value. Numbers take a "min-max" range with either end optional,
+// enums a comma separated list and booleans true or false.
+type specFilter struct {
+	Name   string
+	Type   string
+	Min    *float64
+	Max    *float64
+	Values []string
+}
+
+// wantFacets reports whether the listing should come back as an object with
+// facet counts (?facets=true) instead of the plain product array.
+func wantFacets(c *gin.Context) bool {
+	return c.Query("facets") == "true"
+}
+
+// parseProductFilter reads the facet filters shared by the product listing
+// and search endpoints from the query string.
+func parseProductFilter(c *gin.Context) (productFilter, error) {
+	filter := productFilter{
+		InStock:    c.Query("in_stock") == "true",
+		HasOffer:   c.Query("has_offer") == "true",
+		Attributes: map[string][]string{},
+	}
+
+	for _, bound := range []struct {
+		param string
+		dest  **float64
+	}{{"min_price", &filter.MinPrice}, {"max_price", &filter.MaxPrice}} {
+		value := c.Query(bound.param)
+		if value == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return filter, fmt.Errorf("invalid %s", bound.param)
+		}
+		*bound.dest = &price
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return filter, errors.New("min_price cannot exceed max_price")
+	}
+
+	if value := c.Query("min_rating"); value != "" {
+		rating, err := strconv.ParseFloat(value, 64)
+		if err != nil || rating < 0 || rating > 5 {
+			return filter, errors.New("invalid min_rating")
+		}
+		filter.MinRating = rating
+	}
+
+	for _, value := range strings.Split(c.Query("brand"), ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid brand")
+		}
+		filter.Brands = append(filter.Brands, uint(id))
+	}
+
+	for _, attribute := range variantAttributes {
+		for _, value := range strings.Split(c.Query(attribute), ",") {
+			if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+				filter.Attributes[attribute] = append(filter.Attributes[attribute], value)
+			}
+		}
+	}
+
+	specs, err := parseSpecFilters(c.QueryMap("attr"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Specs = specs
+
+	return filter, nil
+}
+
+func parseSpecFilters(params map[string]string) ([]specFilter, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	var definitions []models.CategoryAttribute
+	if err := db.Db.Where("name IN ?", names).Find(&definitions).Error; err != nil {
+		return nil, err
+	}
+	types := map[string]string{}
+	for _, definition := range definitions {
+		types[definition.Name] = definition.Type
+	}
+
+	var specs []specFilter
+	for _, name := range names {
+		value := strings.TrimSpace(params[name])
+		spec := specFilter{Name: name, Type: types[name]}
+
+		switch spec.Type {
+		case helper.AttributeNumber:
+			bounds := strings.SplitN(value, "-", 2)
+			if len(bounds) == 1 {
+				bounds = append(bounds, bounds[0])
+			}
+			for i, dest := range []**float64{&spec.Min, &spec.Max} {
+				if bound := strings.TrimSpace(bounds[i]); bound != "" {
+					number, err := strconv.ParseFloat(bound, 64)
+					if err != nil {
+						return nil, fmt.Errorf("invalid range for attribute %s", name)
+					}
+					*dest = &number
+				}
+			}
+		case helper.AttributeBoolean:
+			if value != "true" && value != "false" {
+				return nil, fmt.Errorf("attribute %s must be true or false", name)
+			}
+			spec.Values = []string{value}
+		case helper.AttributeEnum:
+			for _, option := range strings.Split(value, ",") {
+				if option = strings.ToLower(strings.TrimSpace(option)); option != "" {
+					spec.Values = append(spec.Values, option)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown attribute %s", name)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+func (filter productFilter) apply(query *gorm.DB) *gorm.DB {
+	if filter.MinPrice != nil {
+		query = query.Where("products.price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("products.price <= ?", *filter.MaxPrice)
+	}
+	if filter.MinRating > 0 {
+		query = query.Where(ratingExpr+" >= ?", filter.MinRating)
+	}
+	if filter.InStock {
+		query = query.Where(inStockExpr)
+	}
+	if filter.HasOffer {
+		query = query.Where(hasOfferExpr)
+	}
+	if len(filter.Brands) > 0 {
+		query = query.Where("products.brand_id IN ?", filter.Brands)
+	}
+	for attribute, values := range filter.Attributes {
+		query = query.Where(fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM product_variants v WHERE v.product_id = products.product_id AND v.deleted_at IS NULL AND LOWER(v.%s) IN ?)",
+			attribute), values)
+	}
+	for _, spec := range filter.Specs {
+		switch spec.Type {
+		case helper.AttributeNumber:
+			number := "(CASE WHEN jsonb_typeof(products.attributes->?) = 'number' THEN (products.attributes->>?)::numeric END)"
+			if spec.Min != nil {
+				query = query.Where(number+" >= ?", spec.Name, spec.Name, *spec.Min)
+			}
+			if spec.Max != nil {
+				query = query.Where(number+" <= ?", spec.Name, spec.Name, *spec.Max)
+			}
+		case helper.AttributeBoolean:
+			query = query.Where("products.attributes->>? = ?", spec.Name, spec.Values[0])
+		case helper.AttributeEnum:
+			if len(spec.Values) > 0 {
+				query = query.Where("LOWER(products.attributes->>?) IN ?", spec.Name, spec.Values)
+			}
+		}
+	}
+	return query
+}
+
+// productFacets counts the products matched by query per category, price
+// bucket, rating and variant attribute. query must not carry a Select.
+func productFacets(query *gorm.DB) (responsemodels.Facets, error) {
+	var rows []struct {
+		ProductID  int
+		CategoryID uint
+		BrandID    *uint
+		Price      float64
+		Rating     float64
+		InStock    bool
+		HasOffer   bool
+	}
+	if err := query.Select(strings.Join([]string{
+		"products.product_id",
+		"products.category_id",
+		"products.brand_id",
+		"products.price",
+		ratingExpr + " AS rating",
+		inStockExpr + " AS in_stock",
+		hasOfferExpr + " AS has_offer",
+	}, ", ")).Find(&rows).Error; err != nil {
+		return responsemodels.Facets{}, err
+	}
+
+	facets := responsemodels.Facets{Total: len(rows), Attributes: map[string][]responsemodels.FacetCount{}}
+	if len(rows) == 0 {
+		return facets, nil
+	}
+
+	categoryCounts := map[uint]int{}
+	brandCounts := map[uint]int{}
+	priceCounts := make([]int, len(priceBreaks)+1)
+	ratingCounts := make([]int, 4)
+	productIDs := make([]int, len(rows))
+	for i, row := range rows {
+		productIDs[i] = row.ProductID
+		categoryCounts[row.CategoryID]++
+		if row.BrandID != nil {
+			brandCounts[*row.BrandID]++
+		}
+		priceCounts[sort.Search(len(priceBreaks), func(i int) bool { return priceBreaks[i] > row.Price })]++
+		for stars := 4; stars >= 1; stars-- {
+			if row.Rating >= float64(stars) {
+				ratingCounts[4-stars]++
+			}
+		}
+		if row.InStock {
+			facets.InStock++
+		}
+		if row.HasOffer {
+			facets.HasOffer++
+		}
+	}
+
+	if categories, err := helper.LoadCategoryIndex(); err == nil {
+		for id, count := range categoryCounts {
+			facets.Categories = append(facets.Categories, responsemodels.FacetCount{
+				Value: strconv.FormatUint(uint64(id), 10),
+				Label: categories[id].CategoryName,
+				Count: count,
+			})
+		}
+		sort.Slice(facets.Categories, func(i, j int) bool {
+			return facets.Categories[i].Count > facets.Categories[j].Count
+		})
+	}
+
+	if len(brandCounts) > 0 {
+		ids := make([]uint, 0, len(brandCounts))
+		for id := range brandCounts {
+			ids = append(ids, id)
+		}
+		var brands []models.Brand
+		if err := db.Db.Where("brand_id IN ?", ids).Find(&brands).Error; err != nil {
+			return facets, err
+		}
+		for _, brand := range brands {
+			facets.Brands = append(facets.Brands, responsemodels.FacetCount{
+				Value: strconv.FormatUint(uint64(brand.BrandID), 10),
+				Label: brand.Name,
+				Count: brandCounts[brand.BrandID],
+			})
+		}
+		sort.Slice(facets.Brands, func(i, j int) bool {
+			return facets.Brands[i].Count > facets.Brands[j].Count
+		})
+	}
+
+	lower := 0.0
+	for i, count := range priceCounts {
+		bucket := responsemodels.FacetCount{Count: count}
+		if i < len(priceBreaks) {
+			bucket.Value = fmt.Sprintf("%g-%g", lower, priceBreaks[i])
+			lower = priceBreaks[i]
+		} else {
+			bucket.Value = fmt.Sprintf("%g-", lower)
+		}
+		if count > 0 {
+			facets.PriceRanges = append(facets.PriceRanges, bucket)
+		}
+	}
+
+	for i, count := range ratingCounts {
+		if count > 0 {
+			facets.Ratings = append(facets.Ratings, responsemodels.FacetCount{
+				Value: strconv.Itoa(4 - i),
+				Label: fmt.Sprintf("%d stars & up", 4-i),
+				Count: count,
+			})
+		}
+	}
+
+	for _, attribute := range variantAttributes {
+		var counts []responsemodels.FacetCount
+		if err := db.Db.Table("product_variants").
+			Select(fmt.Sprintf("LOWER(%s) AS value, COUNT(DISTINCT product_id) AS count", attribute)).
+			Where(fmt.Sprintf("product_id IN ? AND deleted_at IS NULL AND %s <> ''", attribute), productIDs).
+			Group("value").Order("count DESC").
+			Scan(&counts).Error; err != nil {
+			return facets, err
+		}
+		if len(counts) > 0 {
+			facets.Attributes[attribute] = counts
+		}
+	}
+
+	return facets, nil
+}
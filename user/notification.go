@@ -0,0 +1,119 @@
+package user
+
+import (
+	"net/http"
+	"time"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+func ListNotifications(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	page, limit, offset := helper.Paginate(c)
+
+	query := db.Db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if c.Query("unread") == "true" {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.WithFields(log.Fields{
+			"UserID": userID,
+			"error":  err,
+		}).Error("Cannot count notifications")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch notifications"})
+		return
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		log.WithFields(log.Fields{
+			"UserID": userID,
+			"error":  err,
+		}).Error("Cannot fetch notifications")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch notifications"})
+		return
+	}
+
+	response := make([]responsemodels.Notification, len(notifications))
+	for i, n := range notifications {
+		response[i] = responsemodels.Notification{
+			NotificationID: n.NotificationID,
+			Type:           n.Type,
+			Title:          n.Title,
+			Body:           n.Body,
+			OrderID:        n.OrderID,
+			ProductID:      n.ProductID,
+			Read:           n.ReadAt != nil,
+			CreatedAt:      n.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": response,
+		"page":          page,
+		"limit":         limit,
+		"total":         total,
+	})
+}
+
+func UnreadNotificationCount(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	var count int64
+	if err := db.Db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot count notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+func MarkNotificationRead(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+	notificationID := c.Param("id")
+
+	var notification models.Notification
+	if err := db.Db.Where("notification_id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+
+	if notification.ReadAt == nil {
+		if err := db.Db.Model(&notification).Update("read_at", time.Now()).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot update notification"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func MarkAllNotificationsRead(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	result := db.Db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "updated": result.RowsAffected})
+}
@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"math"
@@ -9,9 +10,11 @@ import (
 	"time"
 
 	db "admin/DB"
+	"admin/helper"
 	"admin/middleware"
 	"admin/models"
 	"admin/models/responsemodels"
+	"admin/notify"
 	util "admin/utils"
 
 	"github.com/gin-gonic/gin"
@@ -67,6 +70,7 @@ func Orders(c *gin.Context) {
 	var totalAmount float64
 	var totalQuantity int
 	var orderItems []models.OrderItem
+	var lines []helper.ReservationLine
 
 	var totalDiscount float64
 
@@ -74,22 +78,37 @@ func Orders(c *gin.Context) {
 		productID := item.ProductID
 		product := models.Product{}
 
-		if err := db.Db.First(&product, productID).Error; err != nil {
+		if err := db.Db.Scopes(helper.Published).First(&product, productID).Error; err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found", "product_id": productID})
 			return
 		}
 
-		if product.Quantity < item.Quantity {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient stock for product", "product_id": product.ProductID})
+		var variant *models.ProductVariant
+		available := product.Quantity
+		if item.VariantID != 0 {
+			v, err := helper.FindVariant(db.Db, productID, item.VariantID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found", "variant_id": item.VariantID})
+				return
+			}
+			variant = &v
+			available = v.Quantity
+		}
+
+		if available < item.Quantity {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Insufficient stock for product", "product_id": product.ProductID, "variant_id": item.VariantID})
 			return
 		}
 
-		itemPrice := float64(item.Quantity) * product.Price
+		itemPrice := float64(item.Quantity) * helper.UnitPrice(product, variant)
 
-		var offer models.Offer
+		// Bundle lines carry their share of the bundle price, which already
+		// replaces any product offer.
 		var itemDiscount float64
-		if err := db.Db.Where("product_id = ?", productID).First(&offer).Error; err == nil {
-			itemDiscount = (float64(offer.OfferPercentage) / 100) * itemPrice
+		if item.BundleID != 0 {
+			itemPrice = float64(item.Total)
+		} else if percentage := helper.OfferPercentage(db.Db, productID, item.VariantID); percentage > 0 {
+			itemDiscount = (float64(percentage) / 100) * itemPrice
 			itemPrice -= itemDiscount
 		}
 
@@ -99,19 +118,16 @@ func Orders(c *gin.Context) {
 
 		orderItem := models.OrderItem{
 			ProductID: productID,
+			VariantID: item.VariantID,
+			BundleID:  item.BundleID,
 			Quantity:  item.Quantity,
 			Price:     itemPrice,
 		}
-		orderItems = append(orderItems, orderItem)
-
-		product.Quantity -= item.Quantity
-		if err := db.Db.Save(&product).Error; err != nil {
-			log.WithFields(log.Fields{
-				"error": err,
-			}).Error("error saving product")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product stock"})
-			return
+		if variant != nil {
+			orderItem.SKU = variant.SKU
 		}
+		orderItems = append(orderItems, orderItem)
+		lines = append(lines, helper.ReservationLine{ProductID: productID, VariantID: item.VariantID, Quantity: item.Quantity})
 	}
 
 	var couponDiscount float64
@@ -157,6 +173,12 @@ func Orders(c *gin.Context) {
 			return
 		}
 
+		// Stock is held while the buyer approves the payment and committed
+		// when it is captured; abandoned approvals release it on expiry.
+		if !reserveCheckout(c, payPalOrderID, userID, lines) {
+			return
+		}
+
 		tempOrder := models.TempOrder{
 			OrderID:       payPalOrderID,
 			UserID:        int(userID),
@@ -169,14 +191,42 @@ func Orders(c *gin.Context) {
 			PaymentStatus: "Pending",
 			OrderDate:     time.Now(),
 		}
-		db.Db.Create(&tempOrder)
+		err = db.Db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&tempOrder).Error; err != nil {
+				return err
+			}
+			tempItems := make([]models.TempOrderItem, 0, len(orderItems))
+			for _, item := range orderItems {
+				tempItems = append(tempItems, models.TempOrderItem{
+					TempOrderID: payPalOrderID,
+					ProductID:   item.ProductID,
+					VariantID:   item.VariantID,
+					BundleID:    item.BundleID,
+					SKU:         item.SKU,
+					Quantity:    item.Quantity,
+					Price:       item.Price,
+				})
+			}
+			return tx.Create(&tempItems).Error
+		})
+		if err != nil {
+			releaseCheckout(payPalOrderID)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order"})
+			return
+		}
 
-		c.JSON(http.StatusOK, gin.H{"approval_url": approvalURL})
+		c.JSON(http.StatusOK, gin.H{"approval_url": approvalURL, "reserved_until": time.Now().Add(helper.ReservationTTL)})
 		return
 
 	case "COD":
+		checkoutID := helper.NewCheckoutID()
+		if !reserveCheckout(c, checkoutID, userID, lines) {
+			return
+		}
+		// A no-op once createOrder has committed the reservation.
+		defer releaseCheckout(checkoutID)
 
-		order, err := createOrder(userID, input, orderItems, totalAmount, totalQuantity, totalDiscount, coupon)
+		order, err := createOrder(userID, input, orderItems, totalAmount, totalQuantity, totalDiscount, coupon, checkoutID)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"UserID": userID,
@@ -198,6 +248,14 @@ func Orders(c *gin.Context) {
 			OrderDate:      order.OrderDate,
 		}
 
+		helper.NotifyUser(userID, notify.EventOrderPlaced, gin.H{
+			"OrderID":  order.OrderID,
+			"Quantity": totalQuantity,
+			"Discount": totalDiscount,
+			"Total":    totalAmount,
+			"Method":   order.Method,
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Order placed successfully", "order": orderResponse})
 	case "Wallet":
 		var wallet models.Wallet
@@ -213,6 +271,13 @@ func Orders(c *gin.Context) {
 			return
 		}
 
+		checkoutID := helper.NewCheckoutID()
+		if !reserveCheckout(c, checkoutID, userID, lines) {
+			return
+		}
+		// A no-op once the transaction below has committed the reservation.
+		defer releaseCheckout(checkoutID)
+
 		order := models.Order{
 			UserID:        int(userID),
 			CouponID:      coupon.CouponID,
@@ -255,6 +320,12 @@ func Orders(c *gin.Context) {
 			return
 		}
 
+		if err := helper.CommitReservations(tx, checkoutID, order.OrderID); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit stock"})
+			return
+		}
+
 		if err := tx.Commit().Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 			return
@@ -270,6 +341,15 @@ func Orders(c *gin.Context) {
 			PaymentStatus:  order.PaymentStatus,
 			OrderDate:      order.OrderDate,
 		}
+
+		helper.NotifyUser(userID, notify.EventOrderPlaced, gin.H{
+			"OrderID":  order.OrderID,
+			"Quantity": totalQuantity,
+			"Discount": totalDiscount,
+			"Total":    totalAmount,
+			"Method":   order.Method,
+		})
+
 		c.JSON(http.StatusOK, gin.H{"message": "Order placed successfully", "order": orderResponse})
 
 	default:
@@ -311,15 +391,57 @@ func ReturnOrder(c *gin.Context) {
 		return
 	}
 
-	for _, item := range order.OrderItems {
-		db.Db.Model(&models.Product{}).Where("product_id = ?", item.ProductID).Update("quantity", gorm.Expr("quantity + ?", item.Quantity))
+	var returnedItems []models.OrderItem
+	db.Db.Where("order_id = ?", order.OrderID).Find(&returnedItems)
+	for _, item := range returnedItems {
+		if err := helper.AdjustStock(db.Db, item.ProductID, item.VariantID, item.Quantity,
+			helper.StockChange{Reason: helper.StockReturned, OrderID: order.OrderID}); err != nil {
+			log.WithFields(log.Fields{
+				"OrderID":   order.OrderID,
+				"ProductID": item.ProductID,
+				"error":     err,
+			}).Error("error restocking returned item")
+		}
 	}
 
+	helper.NotifyUser(uint(order.UserID), notify.EventReturnAccepted, gin.H{"OrderID": order.OrderID})
+
 	c.JSON(http.StatusOK, gin.H{"message": "Order returned successfully"})
 }
 
+// reserveCheckout holds the stock for the order lines, answering the request
+// itself when it cannot.
+func reserveCheckout(c *gin.Context, checkoutID string, userID uint, lines []helper.ReservationLine) bool {
+	err := helper.ReserveStock(checkoutID, userID, lines, helper.ReservationTTL)
+	if errors.Is(err, helper.ErrInsufficientStock) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Insufficient stock", "details": err.Error()})
+		return false
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"UserID":     userID,
+			"CheckoutID": checkoutID,
+			"error":      err,
+		}).Error("error reserving stock")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reserve stock"})
+		return false
+	}
+	return true
+}
+
+// releaseCheckout gives back stock still held for a checkout that did not
+// become an order.
+func releaseCheckout(checkoutID string) {
+	if err := helper.ReleaseReservations(db.Db, checkoutID); err != nil {
+		log.WithFields(log.Fields{
+			"CheckoutID": checkoutID,
+			"error":      err,
+		}).Error("error releasing stock")
+	}
+}
+
 func createOrder(userID uint, input models.OrderInput, orderItems []models.OrderItem, totalAmount float64,
-	totalQuantity int, totalDiscount float64, coupon models.Coupon) (*models.Order, error) {
+	totalQuantity int, totalDiscount float64, coupon models.Coupon, checkoutID string) (*models.Order, error) {
 
 	if totalAmount > 1000 {
 		return nil, fmt.Errorf("COD not allowed over Rupees 1000")
@@ -363,6 +485,11 @@ func createOrder(userID uint, input models.OrderInput, orderItems []models.Order
 		return nil, fmt.Errorf("failed to clear cart: %v", err)
 	}
 
+	if err := helper.CommitReservations(tx, checkoutID, order.OrderID); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("failed to commit stock: %v", err)
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %v", err)
 	}
@@ -387,6 +514,43 @@ func CapturePayPalOrder(c *gin.Context) {
 
 	fmt.Printf("Received OrderID (token): %s, PayerID: %s\n", orderID, payerID)
 
+	var tempOrder models.TempOrder
+	if err := db.Db.Where("order_id = ?", orderID).First(&tempOrder).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Temporary order not found"})
+		return
+	}
+	if tempOrder.Status != "Pending" && tempOrder.Status != "Expired" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order is " + tempOrder.Status})
+		return
+	}
+
+	var tempItems []models.TempOrderItem
+	if err := db.Db.Where("temp_order_id = ?", orderID).Find(&tempItems).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if len(tempItems) == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Checkout has no items, please place the order again"})
+		return
+	}
+
+	// Make sure the stock is still held before taking the money. Checkouts
+	// from before reservations existed have none and already took stock.
+	err = helper.HoldReservations(orderID)
+	if errors.Is(err, helper.ErrInsufficientStock) {
+		db.Db.Model(&tempOrder).Updates(map[string]any{"status": "Failed", "payment_status": "Canceled"})
+		c.JSON(http.StatusConflict, gin.H{"error": "Some items are no longer in stock, payment was not taken", "details": err.Error()})
+		return
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.WithFields(log.Fields{
+			"PaymentID": orderID,
+			"error":     err,
+		}).Error("error holding stock")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reserve stock"})
+		return
+	}
+
 	captureRequest := paypal.CaptureOrderRequest{}
 	order, err := client.CaptureOrder(context.Background(), orderID, captureRequest)
 	if err != nil {
@@ -399,18 +563,8 @@ func CapturePayPalOrder(c *gin.Context) {
 		return
 	}
 
-	var tempOrder models.TempOrder
-	if err := db.Db.Where("order_id = ?", orderID).First(&tempOrder).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Temporary order not found"})
-		return
-	}
-
 	tempOrder.Status = "Processing"
 	tempOrder.PaymentStatus = "Completed"
-	if err := db.Db.Save(&tempOrder).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order status"})
-		return
-	}
 	originalOrder := models.Order{
 		PaymentID:     tempOrder.OrderID,
 		UserID:        tempOrder.UserID,
@@ -424,7 +578,38 @@ func CapturePayPalOrder(c *gin.Context) {
 		OrderDate:     tempOrder.OrderDate,
 	}
 
-	if err := db.Db.Create(&originalOrder).Error; err != nil {
+	err = db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&tempOrder).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&originalOrder).Error; err != nil {
+			return err
+		}
+
+		// Build the order from the lines the buyer approved, not from the
+		// cart, which may have changed while PayPal had them.
+		orderItems := make([]models.OrderItem, 0, len(tempItems))
+		for _, item := range tempItems {
+			orderItems = append(orderItems, models.OrderItem{
+				OrderID:   originalOrder.OrderID,
+				ProductID: item.ProductID,
+				VariantID: item.VariantID,
+				BundleID:  item.BundleID,
+				SKU:       item.SKU,
+				Quantity:  item.Quantity,
+				Price:     item.Price,
+			})
+		}
+
+		if err := tx.Create(&orderItems).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id=?", tempOrder.UserID).Delete(&models.Cart{}).Error; err != nil {
+			return err
+		}
+		return helper.CommitReservations(tx, tempOrder.OrderID, originalOrder.OrderID)
+	})
+	if err != nil {
 		log.WithFields(log.Fields{
 			"PaymentID": originalOrder.PaymentID,
 			"error":     err,
@@ -433,35 +618,11 @@ func CapturePayPalOrder(c *gin.Context) {
 		return
 	}
 
-	var cartItems []models.Cart
-	if err := db.Db.Where("user_id = ?", tempOrder.UserID).Find(&cartItems).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch cart items"})
-		return
-	}
-
-	var orderItems []models.OrderItem
-	for _, cartItem := range cartItems {
-		orderItem := models.OrderItem{
-			OrderID:   originalOrder.OrderID,
-			ProductID: cartItem.ProductID,
-			Quantity:  cartItem.Quantity,
-			Price:     (float64(cartItem.Total) / float64(cartItem.Quantity)),
-		}
-		orderItems = append(orderItems, orderItem)
-	}
-
-	if err := db.Db.Create(&orderItems).Error; err != nil {
-		log.WithFields(log.Fields{
-			"OrderID": originalOrder.OrderID,
-			"error":   err,
-		}).Error("error creating orderItems")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order items"})
-		return
-	}
-	if err := db.Db.Where("user_id=?", tempOrder.UserID).Delete(&models.Cart{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot clear cart"})
-		return
-	}
+	helper.NotifyUser(uint(tempOrder.UserID), notify.EventPaymentCaptured, gin.H{
+		"OrderID":   originalOrder.OrderID,
+		"PaymentID": tempOrder.OrderID,
+		"Total":     tempOrder.Total,
+	})
 
 	orderResponse := gin.H{
 		"order_id":       tempOrder.OrderID,
@@ -481,3 +642,36 @@ func CapturePayPalOrder(c *gin.Context) {
 		"order":   orderResponse,
 	})
 }
+
+// CancelPayPalOrder is where PayPal sends buyers who back out of the approval
+// page. The stock held for the checkout goes straight back on the shelf.
+func CancelPayPalOrder(c *gin.Context) {
+	orderID := c.Query("token")
+	if orderID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order ID (token) missing from query parameters"})
+		return
+	}
+
+	var tempOrder models.TempOrder
+	if err := db.Db.Where("order_id = ? AND status = ?", orderID, "Pending").First(&tempOrder).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pending order not found"})
+		return
+	}
+
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := helper.ReleaseReservations(tx, orderID); err != nil {
+			return err
+		}
+		return tx.Model(&tempOrder).Updates(map[string]any{"status": "Canceled", "payment_status": "Canceled"}).Error
+	})
+	if err != nil {
+		log.WithFields(log.Fields{
+			"PaymentID": orderID,
+			"error":     err,
+		}).Error("error canceling checkout")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel order"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Payment canceled, your cart is unchanged"})
+}
@@ -2,73 +2,197 @@ package user
 
 import (
 	"net/http"
+	"strings"
 
 	db "admin/DB"
+	"admin/helper"
 	"admin/models"
 	"admin/models/responsemodels"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+type productRow struct {
+	ProductID     int            `gorm:"column:product_id"`
+	ProductName   string         `gorm:"column:product_name"`
+	Slug          string         `gorm:"column:slug"`
+	Description   string         `gorm:"column:description"`
+	Price         float64        `gorm:"column:price"`
+	OfferDiscount float64        `gorm:"column:offer_discount"`
+	CategoryID    uint           `gorm:"column:category_id"`
+	BrandID       *uint          `gorm:"column:brand_id"`
+	BrandName     string         `gorm:"column:brand_name"`
+	ImgURL        string         `gorm:"column:img_url"`
+	Status        string         `gorm:"column:status"`
+	Quantity      int            `gorm:"column:quantity"`
+	AverageRating float64        `gorm:"column:average_rating"`
+	TotalReviews  int            `gorm:"column:total_reviews"`
+	Attributes    map[string]any `gorm:"column:attributes;serializer:json"`
+	QuestionCount int            `gorm:"column:question_count"`
+	AnsweredCount int            `gorm:"column:answered_count"`
+}
+
+// productColumns are the columns productRow is scanned from.
+var productColumns = strings.Join([]string{
+	"products.product_id",
+	"products.product_name",
+	"products.slug",
+	"products.description",
+	"products.price",
+	"products.category_id",
+	"products.brand_id",
+	brandNameExpr + " AS brand_name",
+	"products.img_url",
+	"products.status",
+	"products.quantity",
+	"products.offer_discount",
+	"products.attributes",
+	ratingExpr + " AS average_rating",
+	reviewsExpr + " AS total_reviews",
+	questionsExpr + " AS question_count",
+	answeredExpr + " AS answered_count",
+}, ", ")
+
 func ViewProducts(c *gin.Context) {
 
-	var dbProducts []struct {
-		ProductID     int     `gorm:"column:product_id"`
-		ProductName   string  `gorm:"column:product_name"`
-		Description   string  `gorm:"column:description"`
-		Price         float64 `gorm:"column:price"`
-		OfferDiscount float64 `gorm:"column:offer_discount"`
-		CategoryID    uint    `gorm:"column:category_id"`
-		ImgURL        string  `gorm:"column:img_url"`
-		Status        string  `gorm:"column:status"`
-		Quantity      int     `gorm:"column:quantity"`
-		AverageRating float64 `gorm:"column:average_rating"`
-		TotalReviews  int     `gorm:"column:total_reviews"`
-	}
-
-	result := db.Db.Raw(`
-		 SELECT 
-			  p.product_id,
-			  p.product_name,
-			  p.description,
-			  p.price,
-			  p.category_id,
-			  p.img_url,
-			  p.status,
-			  p.quantity,
-			  p.offer_discount,
-			  COALESCE(AVG(r.rating), 0) as average_rating,
-			  COUNT(DISTINCT r.review_rating_id) as total_reviews
-		 FROM products p
-		 LEFT JOIN review_ratings r ON r.product_id = p.product_id
-		 GROUP BY p.product_id
-	`).Find(&dbProducts)
+	var dbProducts []productRow
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := filter.apply(db.Db.Model(&models.Product{}).Scopes(helper.Published)).Session(&gorm.Session{})
+
+	result := query.Select(productColumns).Order("products.product_id ASC").Find(&dbProducts)
 
 	if result.Error != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": result.Error.Error()})
 		return
 	}
 
+	responseProducts, err := buildProductResponses(dbProducts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Facet counts change the response to an object, so clients ask for them.
+	if wantFacets(c) {
+		facets, err := productFacets(query)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"products": responseProducts, "facets": facets})
+		return
+	}
+
 	if len(dbProducts) == 0 {
 		c.JSON(http.StatusOK, gin.H{"message": "No products listed"})
 		return
 	}
 
+	c.JSON(http.StatusOK, responseProducts)
+}
+
+// buildProductResponses adds variants, images, reviews, breadcrumbs and
+// attributes to scanned product rows.
+func buildProductResponses(dbProducts []productRow) ([]responsemodels.Products, error) {
+	if len(dbProducts) == 0 {
+		return []responsemodels.Products{}, nil
+	}
+
+	categories, err := helper.LoadCategoryIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(dbProducts))
+	for i, dbProduct := range dbProducts {
+		ids[i] = dbProduct.ProductID
+	}
+	lowestPrices, err := helper.LowestPrices(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []models.ProductVariant
+	if err := db.Db.Where("product_id IN ?", ids).Order("variant_id ASC").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	variantsByProduct := map[int][]models.ProductVariant{}
+	for _, variant := range variants {
+		variantsByProduct[variant.ProductID] = append(variantsByProduct[variant.ProductID], variant)
+	}
+
+	var images []models.ProductImage
+	if err := db.Db.Where("product_id IN ?", ids).Order("position ASC, image_id ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	imagesByProduct := map[int][]models.ProductImage{}
+	for _, image := range images {
+		imagesByProduct[image.ProductID] = append(imagesByProduct[image.ProductID], image)
+	}
+
+	// The three latest reviews of every product in one query.
+	var reviews []models.ReviewRating
+	if err := db.Db.Raw(`
+		SELECT review_rating_id, user_id, product_id, rating, comment, created_at FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY product_id ORDER BY created_at DESC) AS position
+			FROM review_ratings WHERE product_id IN ?
+		) r WHERE position <= 3
+		ORDER BY created_at DESC`, ids).Scan(&reviews).Error; err != nil {
+		return nil, err
+	}
+	reviewsByProduct := map[int][]models.ReviewRating{}
+	for _, review := range reviews {
+		reviewsByProduct[review.ProductID] = append(reviewsByProduct[review.ProductID], review)
+	}
+
 	responseProducts := make([]responsemodels.Products, len(dbProducts))
+	schemas := map[uint][]models.CategoryAttribute{}
 
 	for i, dbProduct := range dbProducts {
+		variants := variantsByProduct[dbProduct.ProductID]
+
+		quantity := dbProduct.Quantity
+		variantResponses := make([]responsemodels.Variant, len(variants))
+		if len(variants) > 0 {
+			quantity = 0
+		}
+		for j, variant := range variants {
+			quantity += variant.Quantity
+			variantResponses[j] = responsemodels.Variant{
+				VariantID: variant.VariantID,
+				SKU:       variant.SKU,
+				Color:     variant.Color,
+				Fabric:    variant.Fabric,
+				Size:      variant.Size,
+				Price:     dbProduct.Price + variant.PriceDelta,
+				Quantity:  variant.Quantity,
+				Images:    variant.Images,
+			}
+		}
+
+		images := imagesByProduct[dbProduct.ProductID]
+		imageResponses := make([]responsemodels.Image, len(images))
+		for j, image := range images {
+			imageResponses[j] = responsemodels.Image{
+				ImageID:      image.ImageID,
+				ThumbnailURL: image.ThumbnailURL,
+				MediumURL:    image.MediumURL,
+				LargeURL:     image.LargeURL,
+			}
+		}
+
 		status := "Available"
-		if dbProduct.Quantity == 0 {
+		if quantity == 0 {
 			status = "Out of stock"
 		}
 
-		var recentReviews []models.ReviewRating
-		db.Db.Table("review_ratings").
-			Select("review_rating_id, user_id, rating, comment, created_at").
-			Where("product_id = ?", dbProduct.ProductID).
-			Order("created_at DESC").
-			Limit(3).
-			Find(&recentReviews)
-
+		recentReviews := reviewsByProduct[dbProduct.ProductID]
 		reviewResponses := make([]responsemodels.ReviewRating, len(recentReviews))
 		for j, review := range recentReviews {
 			reviewResponses[j] = responsemodels.ReviewRating{
@@ -80,21 +204,51 @@ func ViewProducts(c *gin.Context) {
 			}
 		}
 
+		schema, ok := schemas[dbProduct.CategoryID]
+		if !ok {
+			schema, _ = categories.AttributeSchema(db.Db, dbProduct.CategoryID)
+			schemas[dbProduct.CategoryID] = schema
+		}
+
+		lowest, ok := lowestPrices[dbProduct.ProductID]
+		if !ok {
+			lowest = dbProduct.Price * (100 - dbProduct.OfferDiscount) / 100
+		}
+
 		responseProducts[i] = responsemodels.Products{
 			ProductID:     dbProduct.ProductID,
 			ProductName:   dbProduct.ProductName,
+			Slug:          dbProduct.Slug,
+			CanonicalURL:  helper.ProductURL(dbProduct.Slug),
 			Description:   dbProduct.Description,
 			Price:         dbProduct.Price,
 			OfferDiscount: dbProduct.OfferDiscount,
+			LowestPrice:   lowest,
 			CategoryID:    dbProduct.CategoryID,
+			BrandID:       dbProduct.BrandID,
+			BrandName:     dbProduct.BrandName,
+			Breadcrumbs:   toBreadcrumbs(categories.Breadcrumbs(dbProduct.CategoryID)),
 			ImgURL:        dbProduct.ImgURL,
 			Status:        status,
-			Quantity:      dbProduct.Quantity,
+			Quantity:      quantity,
 			AverageRating: dbProduct.AverageRating,
 			TotalReviews:  dbProduct.TotalReviews,
 			RecentReviews: reviewResponses,
+			Variants:      variantResponses,
+			Images:        imageResponses,
+			Attributes:    helper.AttributeValues(schema, dbProduct.Attributes),
+			QuestionCount: dbProduct.QuestionCount,
+			AnsweredCount: dbProduct.AnsweredCount,
 		}
 	}
 
-	c.JSON(http.StatusOK, responseProducts)
+	return responseProducts, nil
+}
+
+func toBreadcrumbs(path []models.Breadcrumb) []responsemodels.Breadcrumb {
+	crumbs := make([]responsemodels.Breadcrumb, len(path))
+	for i, crumb := range path {
+		crumbs[i] = responsemodels.Breadcrumb{CategoryID: crumb.CategoryID, Name: crumb.Name, Slug: crumb.Slug}
+	}
+	return crumbs
 }
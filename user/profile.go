@@ -11,6 +11,7 @@ import (
 	"admin/middleware"
 	"admin/models"
 	"admin/models/responsemodels"
+	"admin/notify"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -201,29 +202,40 @@ func CancelOrders(c *gin.Context) {
 		return
 	}
 
-	if err := db.Db.Where("order_id = ? AND product_id = ?", orderID, productID).First(&orderItem).Error; err != nil {
+	itemQuery := db.Db.Where("order_id = ? AND product_id = ?", orderID, productID)
+	if variantID := c.Query("variant_id"); variantID != "" {
+		itemQuery = itemQuery.Where("variant_id = ?", variantID)
+	}
+	if err := itemQuery.First(&orderItem).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"message": "Order item not found"})
 		return
 	}
 
-	// Update product quantity
-	var product models.Product
-	if err := db.Db.First(&product, productID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Product not found"})
-		return
+	// A bundle is canceled as a whole, restocking every component.
+	items := []models.OrderItem{orderItem}
+	if orderItem.BundleID != 0 {
+		if err := db.Db.Where("order_id = ? AND bundle_id = ?", orderID, orderItem.BundleID).Find(&items).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to load bundle items"})
+			return
+		}
 	}
 
-	product.Quantity += orderItem.Quantity
-
-	if err := db.Db.Save(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update product quantity"})
-		return
+	var refundAmount float64
+	for _, item := range items {
+		// Update product quantity
+		if err := helper.AdjustStock(db.Db, item.ProductID, item.VariantID, item.Quantity,
+			helper.StockChange{Reason: helper.StockOrderCanceled, OrderID: item.OrderID}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to update product quantity"})
+			return
+		}
+		refundAmount += item.Price * float64(item.Quantity)
 	}
 
 	// Refund logic if payment was through PayPal
+	var refunded float64
 	if orders.Method == "Paypal" {
 		if err := db.Db.Where("user_id=?", userID).First(&wallet).Error; err == nil {
-			wallet.Balance += orderItem.Price * float64(orderItem.Quantity)
+			wallet.Balance += refundAmount
 			if err := db.Db.Save(&wallet).Error; err != nil {
 				log.WithFields(log.Fields{
 					"UserID": userID,
@@ -234,7 +246,7 @@ func CancelOrders(c *gin.Context) {
 			walletTransaction := models.WalletTransaction{
 				UserID:          userID,
 				OrderID:         uint(orderID),
-				Amount:          orderItem.Price * float64(orderItem.Quantity),
+				Amount:          refundAmount,
 				TransactionType: "Credit",
 				Description:     "Refund for Product #" + strconv.Itoa(productID),
 			}
@@ -246,10 +258,11 @@ func CancelOrders(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot create transaction"})
 				return
 			}
+			refunded = walletTransaction.Amount
 		} else if err == gorm.ErrRecordNotFound {
 			newWallet := models.Wallet{
 				UserID:  userID,
-				Balance: orderItem.Price * float64(orderItem.Quantity),
+				Balance: refundAmount,
 			}
 			if err := db.Db.Create(&newWallet).Error; err != nil {
 				log.WithFields(log.Fields{
@@ -258,11 +271,13 @@ func CancelOrders(c *gin.Context) {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wallet"})
 				return
 			}
+			wallet = newWallet
+			refunded = newWallet.Balance
 		}
 	}
 
 	// Remove the canceled order item
-	if err := db.Db.Delete(&orderItem).Error; err != nil {
+	if err := db.Db.Delete(&items).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to cancel order item"})
 		return
 	}
@@ -277,6 +292,20 @@ func CancelOrders(c *gin.Context) {
 		}
 	}
 
+	helper.NotifyUser(userID, notify.EventItemCanceled, gin.H{
+		"OrderID":       orderID,
+		"ProductID":     productID,
+		"Quantity":      orderItem.Quantity,
+		"OrderCanceled": orders.Status == "Canceled",
+	})
+	if refunded > 0 {
+		helper.NotifyUser(userID, notify.EventWalletRefunded, gin.H{
+			"OrderID": orderID,
+			"Amount":  refunded,
+			"Balance": wallet.Balance,
+		})
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Order item canceled successfully"})
 }
 
@@ -0,0 +1,183 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ListQuestions returns the visible questions on a product, newest first.
+func ListQuestions(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Scopes(helper.Published).Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	page, limit, offset := helper.Paginate(c)
+	query := db.Db.Model(&models.ProductQuestion{}).
+		Where("product_id = ? AND status = ?", product.ProductID, helper.ModerationVisible)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch questions"})
+		return
+	}
+
+	var questions []models.ProductQuestion
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&questions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch questions"})
+		return
+	}
+
+	response, err := helper.QuestionResponses(questions, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot fetch answers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"questions": response,
+		"page":      page,
+		"limit":     limit,
+		"total":     total,
+	})
+}
+
+func AskQuestion(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	var product models.Product
+	if err := db.Db.Scopes(helper.Published).Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var input models.QuestionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	question := models.ProductQuestion{
+		ProductID: product.ProductID,
+		UserID:    userID,
+		Body:      strings.TrimSpace(input.Body),
+		Status:    helper.ModerationVisible,
+	}
+	if err := db.Db.Create(&question).Error; err != nil {
+		log.WithFields(log.Fields{
+			"UserID":    userID,
+			"ProductID": product.ProductID,
+			"error":     err,
+		}).Error("Cannot create question")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not post question"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Question posted", "question_id": question.QuestionID})
+}
+
+// AnswerQuestion lets customers who received the product answer questions
+// about it.
+func AnswerQuestion(c *gin.Context) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	var question models.ProductQuestion
+	if err := db.Db.Where("question_id = ? AND status = ?", c.Param("id"), helper.ModerationVisible).First(&question).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Question not found"})
+		return
+	}
+
+	verified, err := helper.IsVerifiedBuyer(userID, question.ProductID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot check purchase history"})
+		return
+	}
+	if !verified {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only customers who received this product can answer"})
+		return
+	}
+
+	var input models.AnswerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	answer := models.ProductAnswer{
+		QuestionID: question.QuestionID,
+		UserID:     &userID,
+		Body:       strings.TrimSpace(input.Body),
+		Status:     helper.ModerationVisible,
+	}
+	if err := db.Db.Create(&answer).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not post answer"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Answer posted", "answer_id": answer.AnswerID})
+}
+
+var errAnswerNotFound = errors.New("answer not found")
+
+// UpvoteAnswer counts one vote per user; voting again has no effect.
+func UpvoteAnswer(c *gin.Context) {
+	voteAnswer(c, true)
+}
+
+func RemoveAnswerVote(c *gin.Context) {
+	voteAnswer(c, false)
+}
+
+func voteAnswer(c *gin.Context, up bool) {
+	claims, _ := middleware.GetClaims(c)
+	userID := claims.ID
+
+	var answer models.ProductAnswer
+	err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("answer_id = ? AND status = ?", c.Param("id"), helper.ModerationVisible).
+			First(&answer).Error; err != nil {
+			return errAnswerNotFound
+		}
+
+		vote := models.AnswerVote{AnswerID: answer.AnswerID, UserID: userID}
+		var result *gorm.DB
+		delta := 1
+		if up {
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&vote)
+		} else {
+			result = tx.Where("answer_id = ? AND user_id = ?", answer.AnswerID, userID).Delete(&models.AnswerVote{})
+			delta = -1
+		}
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		answer.Upvotes += delta
+		return tx.Model(&answer).Update("upvotes", gorm.Expr("upvotes + ?", delta)).Error
+	})
+	if errors.Is(err, errAnswerNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Answer not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not record vote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"answer_id": answer.AnswerID, "upvotes": answer.Upvotes})
+}
@@ -0,0 +1,125 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const viewSessionCookie = "rv_session"
+
+// ProductDetail returns a single published product and records the view for
+// the logged-in user or the anonymous session cookie.
+func ProductDetail(c *gin.Context) {
+	showProduct(c, "products.product_id = ?", c.Param("id"))
+}
+
+func showProduct(c *gin.Context, query string, args ...any) {
+	var rows []productRow
+	if err := db.Db.Model(&models.Product{}).Scopes(helper.Published).
+		Select(productColumns).
+		Where(query, args...).
+		Find(&rows).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching product"})
+		return
+	}
+	if len(rows) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	products, err := buildProductResponses(rows)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching product"})
+		return
+	}
+
+	userID, sessionID := viewer(c, true)
+	if err := helper.RecordView(rows[0].ProductID, userID, sessionID); err != nil {
+		log.WithFields(log.Fields{
+			"ProductID": rows[0].ProductID,
+			"error":     err,
+		}).Warn("Cannot record product view")
+	}
+
+	c.JSON(http.StatusOK, products[0])
+}
+
+// RecentlyViewed lists the distinct products the shopper looked at most
+// recently, with their current price and stock.
+func RecentlyViewed(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(helper.MaxRecentlyViewed)))
+	if err != nil || limit < 1 || limit > helper.MaxRecentlyViewed {
+		limit = helper.MaxRecentlyViewed
+	}
+
+	userID, sessionID := viewer(c, false)
+	if userID == 0 && sessionID == "" {
+		c.JSON(http.StatusOK, gin.H{"products": []responsemodels.RecentlyViewed{}})
+		return
+	}
+
+	query := db.Db.Model(&models.Product{}).Scopes(helper.Published).
+		Select(`products.product_id, products.product_name, products.img_url, products.price, products.offer_discount,
+			COALESCE((SELECT SUM(pv.quantity) FROM product_variants pv
+				WHERE pv.product_id = products.product_id AND pv.deleted_at IS NULL), products.quantity) AS quantity,
+			MAX(v.viewed_at) AS viewed_at`).
+		Joins("JOIN product_views v ON v.product_id = products.product_id")
+	if userID != 0 {
+		query = query.Where("v.user_id = ?", userID)
+	} else {
+		query = query.Where("v.user_id IS NULL AND v.session_id = ?", sessionID)
+	}
+
+	var products []responsemodels.RecentlyViewed
+	if err := query.Group("products.product_id").Order("viewed_at DESC").Limit(limit).Scan(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching recently viewed products"})
+		return
+	}
+
+	for i := range products {
+		products[i].FinalPrice = products[i].Price * float64(100-products[i].OfferDiscount) / 100
+		products[i].InStock = products[i].Quantity > 0
+	}
+
+	c.JSON(http.StatusOK, gin.H{"products": products})
+}
+
+// viewer identifies who is browsing. Logged-in shoppers take over the views
+// of their anonymous session; with create set, anonymous visitors without a
+// session cookie are given one.
+func viewer(c *gin.Context, create bool) (uint, string) {
+	sessionID, _ := c.Cookie(viewSessionCookie)
+
+	if claims, ok := c.Get("claims"); ok {
+		if customClaims, ok := claims.(*middleware.Claims); ok {
+			if err := helper.ClaimSessionViews(customClaims.ID, sessionID); err != nil {
+				log.WithFields(log.Fields{
+					"UserID": customClaims.ID,
+					"error":  err,
+				}).Warn("Cannot claim session views")
+			}
+			return customClaims.ID, ""
+		}
+	}
+
+	if sessionID == "" && create {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			return 0, ""
+		}
+		sessionID = hex.EncodeToString(buf)
+		c.SetCookie(viewSessionCookie, sessionID, 365*24*60*60, "/", "", false, true)
+	}
+	return 0, sessionID
+}
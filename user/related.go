@@ -0,0 +1,41 @@
+package user
+
+import (
+	"net/http"
+	"strconv"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+func RelatedProducts(c *gin.Context) {
+	var product models.Product
+	if err := db.Db.Scopes(helper.Published).Where("product_id = ?", c.Param("id")).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "8"))
+	if err != nil || limit < 1 || limit > helper.MaxRelated {
+		limit = 8
+	}
+
+	related, err := helper.RelatedProducts(product)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"ProductID": product.ProductID,
+			"error":     err,
+		}).Error("Cannot load related products")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Cannot load related products"})
+		return
+	}
+	if len(related) > limit {
+		related = related[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"product_id": product.ProductID, "related": related})
+}
@@ -1,34 +1,35 @@
 package user
 
 import (
-	"log"
+	"errors"
 	"net/http"
-	"time"
 
 	db "admin/DB"
 	"admin/helper"
 	"admin/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func ResendOTP(c *gin.Context) {
 	Email := c.Param("email")
 
-	otp, err := helper.GenerateOTP()
-	if err != nil {
-		log.Fatal("Error in generating otp")
-	}
-	go helper.SendEmail(Email, otp)
-	newOtpRecord := models.OTP{
-		Email:  Email,
-		Code:   otp,
-		Expiry: time.Now().Add(time.Minute * 5),
+	var pending models.TempUser
+	if err := db.Db.Where("email = ?", Email).First(&pending).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No pending signup for this email"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
 	}
-	result := db.Db.Model(&models.OTP{}).Where("email = ?", Email).Updates(newOtpRecord)
-	if result.Error != nil {
-		log.Println("Error updating OTP record:", result.Error)
-	} else {
-		c.JSON(http.StatusOK, gin.H{"message": "OTP resend succesfull"})
+
+	otp, err := helper.IssueOTP(Email, helper.OTPPurposeSignup)
+	if err != nil {
+		otpError(c, err)
+		return
 	}
 
+	sendSignupOTP(pending, c.Query("via"), otp)
+	c.JSON(http.StatusOK, gin.H{"message": "OTP resend succesfull"})
 }
@@ -2,10 +2,15 @@ package user
 
 import (
 	"net/http"
+	"strconv"
 
 	db "admin/DB"
+	"admin/helper"
 	"admin/models"
+	"admin/models/responsemodels"
 	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 func SearchProducts(c *gin.Context) {
@@ -14,28 +19,77 @@ func SearchProducts(c *gin.Context) {
 	order := c.Query("order")
 	categoryID := c.Query("categoryID")
 
+	// Filtering by a category includes products of all its subcategories.
+	var categoryIDs []uint
+	if categoryID != "" {
+		parentID, err := strconv.Atoi(categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid categoryID"})
+			return
+		}
+		if categoryIDs, err = helper.DescendantIDs(db.Db, uint(parentID)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching categories"})
+			return
+		}
+	}
+
+	filter, err := parseProductFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var products []models.Product
-	db := db.Db.Model(&models.Product{}).Where("product_name ILIKE ?", "%"+query+"%")
+	db := db.Db.Model(&models.Product{}).Scopes(helper.Published)
+
+	// Words match as prefixes against the weighted search vector; the
+	// trigram fallback catches misspelt product names.
+	tsQuery := helper.PrefixTSQuery(query)
+	if tsQuery != "" {
+		db = db.Where("products.search_vector @@ to_tsquery('english', ?) OR ? <% products.product_name", tsQuery, query)
+	}
 
 	if categoryID != "" {
-		db = db.Where("category_id = ?", categoryID)
+		db = db.Where("category_id IN ?", categoryIDs)
+	}
+
+	db = filter.apply(db).Session(&gorm.Session{})
+
+	var facets *responsemodels.Facets
+	if wantFacets(c) {
+		counts, err := productFacets(db)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching facets"})
+			return
+		}
+		facets = &counts
+	}
+
+	if tsQuery != "" {
+		db = db.Select(`products.*,
+			ts_headline('english', products.description, to_tsquery('english', ?),
+				'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, ShortWord=2') AS snippet,
+			ts_rank_cd(products.search_vector, to_tsquery('english', ?)) +
+				word_similarity(?, products.product_name) AS relevance`,
+			tsQuery, tsQuery, query)
 	}
 
 	switch sort {
+	case "", "relevance":
+		if tsQuery != "" {
+			db = db.Order("relevance DESC")
+		}
+		db = db.Order("product_id ASC")
 	case "popularity":
-		db = db.Order("popularity " + order)
+		db = db.Order("popularity " + helper.SortDirection(order, "DESC"))
 	case "price":
-		db = db.Order("price " + order)
+		db = db.Order("price " + helper.SortDirection(order, "ASC"))
 	case "new_arrivals":
-		db = db.Order("created_at " + order)
+		db = db.Order("created_at " + helper.SortDirection(order, "DESC"))
 	case "featured":
-		db = db.Order("featured " + order)
+		db = db.Order("featured " + helper.SortDirection(order, "DESC"))
 	case "name":
-		if order == "asc" {
-			db = db.Order("product_name ASC")
-		} else {
-			db = db.Order("product_name DESC")
-		}
+		db = db.Order("product_name " + helper.SortDirection(order, "DESC"))
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort parameter"})
 		return
@@ -46,5 +100,22 @@ func SearchProducts(c *gin.Context) {
 		return
 	}
 
+	if err := helper.RecordSearch(query, len(products)); err != nil {
+		log.WithFields(log.Fields{
+			"query": query,
+			"error": err,
+		}).Warn("Cannot record search term")
+	}
+
+	if categories, err := helper.LoadCategoryIndex(); err == nil {
+		for i := range products {
+			products[i].Breadcrumbs = categories.Breadcrumbs(products[i].CategoryID)
+		}
+	}
+
+	if facets != nil {
+		c.JSON(http.StatusOK, gin.H{"products": products, "facets": facets})
+		return
+	}
 	c.JSON(http.StatusOK, products)
 }
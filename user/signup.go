@@ -1,16 +1,18 @@
 package user
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"time"
 
 	db "admin/DB"
 	"admin/helper"
 	"admin/models"
+	"admin/notify"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 func SignUp(c *gin.Context) {
@@ -51,34 +53,75 @@ func SignUp(c *gin.Context) {
 		return
 	}
 
+	db.Db.Raw(`SELECT COUNT(*) FROM users where phonenumber = ? AND deleted_at IS NULL`, input.PhoneNumber).Scan(&count)
+	if count != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": "already registered phone number",
+		})
+		return
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
 		return
 	}
 
-	otp, err := helper.GenerateOTP()
+	otp, err := helper.IssueOTP(input.Email, helper.OTPPurposeSignup)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating OTP"})
+		otpError(c, err)
 		return
 	}
 
-	newOtpRecord := models.OTP{
-		Email:  input.Email,
-		Code:   otp,
-		Expiry: time.Now().Add(time.Minute * 5),
-	}
-	db.Db.Create(&newOtpRecord)
-
-	go helper.SendEmail(input.Email, otp)
-
 	user := models.TempUser{
 		UserName:    input.UserName,
 		Email:       input.Email,
 		Password:    string(hashedPassword),
 		PhoneNumber: input.PhoneNumber,
 	}
-	db.Db.Create(&user)
+	if err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("email = ?", input.Email).Delete(&models.TempUser{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&user).Error
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save signup"})
+		return
+	}
+
+	sendSignupOTP(user, input.VerifyVia, otp)
 
-	c.JSON(http.StatusOK, gin.H{"message": "OTP send successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "OTP send successfully", "verify_via": viaOrDefault(input.VerifyVia)})
+}
+
+// sendSignupOTP delivers the signup OTP by e-mail, or by SMS to the phone
+// number given at signup. The OTP itself is always keyed on the e-mail.
+func sendSignupOTP(user models.TempUser, via, otp string) {
+	data := gin.H{"Code": otp, "ExpiresIn": "5 minutes"}
+	if via == "sms" {
+		notify.SMS(user.PhoneNumber, notify.EventSignupOTP, data)
+		return
+	}
+	notify.Email(user.Email, notify.EventSignupOTP, data)
+}
+
+// otpError maps the OTP helper errors onto HTTP responses.
+func otpError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, helper.ErrOTPInvalid), errors.Is(err, helper.ErrOTPExpired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, helper.ErrOTPTooManyAttempts),
+		errors.Is(err, helper.ErrOTPCooldown),
+		errors.Is(err, helper.ErrOTPDailyLimit):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error processing OTP"})
+	}
+}
+
+func viaOrDefault(via string) string {
+	if via == "" {
+		return "email"
+	}
+	return via
 }
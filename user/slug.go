@@ -0,0 +1,90 @@
+package user
+
+import (
+	"net/http"
+	"sort"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/models"
+	"admin/models/responsemodels"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProductBySlug returns the product behind a slug. Slugs the product had
+// before a rename answer with a permanent redirect to the current one.
+func ProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	var count int64
+	db.Db.Model(&models.Product{}).Scopes(helper.Published).Where("slug = ?", slug).Count(&count)
+	if count == 0 {
+		if id, ok := helper.ResolveRedirect(helper.SlugProduct, slug); ok {
+			var product models.Product
+			if err := db.Db.Scopes(helper.Published).Select("slug").First(&product, id).Error; err == nil {
+				redirectSlug(c, helper.ProductURL(product.Slug), product.Slug)
+				return
+			}
+		}
+	}
+
+	showProduct(c, "products.slug = ?", slug)
+}
+
+// CategoryBySlug returns a category with its breadcrumbs and direct
+// subcategories, redirecting old slugs like ProductBySlug.
+func CategoryBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	categories, err := helper.LoadCategoryIndex()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+
+	var category models.Category
+	found := false
+	for _, candidate := range categories {
+		if candidate.Slug == slug {
+			category, found = candidate, true
+			break
+		}
+	}
+	if !found {
+		if id, ok := helper.ResolveRedirect(helper.SlugCategory, slug); ok {
+			if target, ok := categories[uint(id)]; ok {
+				redirectSlug(c, helper.CategoryURL(target.Slug), target.Slug)
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	children := []responsemodels.Breadcrumb{}
+	for _, child := range categories {
+		if child.ParentID != nil && *child.ParentID == category.CategoryID {
+			children = append(children, responsemodels.Breadcrumb{CategoryID: child.CategoryID, Name: child.CategoryName, Slug: child.Slug})
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].Name < children[j].Name })
+
+	var products int64
+	db.Db.Model(&models.Product{}).Scopes(helper.Published).Where("category_id = ?", category.CategoryID).Count(&products)
+
+	c.JSON(http.StatusOK, gin.H{
+		"category_id":   category.CategoryID,
+		"name":          category.CategoryName,
+		"slug":          category.Slug,
+		"canonical_url": helper.CategoryURL(category.Slug),
+		"breadcrumbs":   toBreadcrumbs(categories.Breadcrumbs(category.CategoryID)),
+		"subcategories": children,
+		"product_count": products,
+	})
+}
+
+func redirectSlug(c *gin.Context, location, slug string) {
+	c.Header("Location", location)
+	c.JSON(http.StatusMovedPermanently, gin.H{"slug": slug, "canonical_url": location})
+}
@@ -0,0 +1,118 @@
+package user
+
+import (
+	"errors"
+	"net/http"
+
+	db "admin/DB"
+	"admin/helper"
+	"admin/middleware"
+	"admin/models"
+	"admin/notify"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// errSharedPhone means more than one account carries the phone number, so an
+// OTP sent to it cannot tell which account to log into.
+var errSharedPhone = errors.New("phone number belongs to more than one account")
+
+// userByPhone finds the only account with the phone number.
+func userByPhone(phone string) (models.User, error) {
+	var users []models.User
+	if err := db.Db.Where("phonenumber = ?", phone).Limit(2).Find(&users).Error; err != nil {
+		return models.User{}, err
+	}
+	switch len(users) {
+	case 0:
+		return models.User{}, gorm.ErrRecordNotFound
+	case 1:
+		return users[0], nil
+	default:
+		return models.User{}, errSharedPhone
+	}
+}
+
+func RequestLoginOTP(c *gin.Context) {
+	var input models.PhoneOTPRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if message, err := helper.ValidateAll(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+
+	// Unknown numbers get the same response so the endpoint cannot be used
+	// to find out which phone numbers are registered.
+	// Numbers shared by several accounts get no OTP either.
+	if _, err := userByPhone(input.PhoneNumber); err != nil {
+		if errors.Is(err, errSharedPhone) {
+			log.WithFields(log.Fields{
+				"error": err,
+			}).Warn("login OTP refused for shared phone number")
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "OTP send successfully"})
+		return
+	}
+
+	otp, err := helper.IssueOTP(input.PhoneNumber, helper.OTPPurposeLogin)
+	if err != nil {
+		otpError(c, err)
+		return
+	}
+
+	notify.SMS(input.PhoneNumber, notify.EventLoginOTP, gin.H{"Code": otp, "ExpiresIn": "5 minutes"})
+	c.JSON(http.StatusOK, gin.H{"message": "OTP send successfully"})
+}
+
+func LoginWithOTP(c *gin.Context) {
+	var input models.PhoneOTPLogin
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if message, err := helper.ValidateAll(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": message})
+		return
+	}
+
+	if err := helper.CheckOTP(input.PhoneNumber, helper.OTPPurposeLogin, input.Code); err != nil {
+		otpError(c, err)
+		return
+	}
+
+	user, err := userByPhone(input.PhoneNumber)
+	if errors.Is(err, errSharedPhone) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This phone number is linked to more than one account, log in with your email"})
+		return
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"error": err,
+		}).Error("user for verified login OTP not found")
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid phone number or OTP"})
+		return
+	}
+
+	if user.Status == "Blocked" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User has been blocked by the Admin"})
+		return
+	}
+
+	token, err := middleware.CreateToken("user", user.Email, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error Generating jwt"})
+		return
+	}
+	c.Header("Authorization", "Bearer "+token)
+	c.JSON(http.StatusOK, gin.H{"message": "Login successfull", "token": token})
+}
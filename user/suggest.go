@@ -0,0 +1,29 @@
+package user
+
+import (
+	"net/http"
+
+	"admin/helper"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchSuggestions backs the search box autocomplete: GET
+// /search/suggestions?q=sof returns products, categories and popular
+// queries for the prefix.
+func SearchSuggestions(c *gin.Context) {
+	query := helper.NormalizeTerm(c.Query("q"))
+	if len(query) < helper.MinSuggestLength {
+		c.JSON(http.StatusOK, gin.H{"products": []any{}, "categories": []any{}, "queries": []any{}})
+		return
+	}
+
+	suggestions, err := helper.Suggest(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error fetching suggestions"})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, suggestions)
+}
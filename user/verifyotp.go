@@ -2,11 +2,10 @@ package user
 
 import (
 	"errors"
-	"log"
 	"net/http"
-	"time"
 
 	db "admin/DB"
+	"admin/helper"
 	"admin/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -20,30 +19,18 @@ func VerifyOTP(c *gin.Context) {
 		return
 	}
 
-	var otp models.OTP
-	log.Printf("Email: %s, OTP: %s", input.Email, input.Code)
-
-	if err := db.Db.Where("email = ? AND code = ?", input.Email, input.Code).First(&otp).Error; err != nil {
+	var user models.TempUser
+	if err := db.Db.Where("email = ?", input.Email).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid OTP"})
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No pending signup for this email"})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		return
 	}
 
-	if time.Now().After(otp.Expiry) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "OTP has expired"})
-		return
-	}
-
-	var user models.TempUser
-	if err := db.Db.Where("email = ?", input.Email).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "User not found"})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	if err := helper.CheckOTP(input.Email, helper.OTPPurposeSignup, input.Code); err != nil {
+		otpError(c, err)
 		return
 	}
 
@@ -54,22 +41,15 @@ func VerifyOTP(c *gin.Context) {
 		PhoneNumber: user.PhoneNumber,
 	}
 
-	log.Printf("New User Data: %+v\n", newUser)
-
-	if err := db.Db.Create(&newUser).Error; err != nil {
+	if err := db.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+		return tx.Where("email = ?", input.Email).Delete(&models.TempUser{}).Error
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
-	if err := db.Db.Where("email = ?", input.Email).Delete(&user).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete temp user"})
-		return
-	}
-
-	if err := db.Db.Where("email = ?", input.Email).Delete(&otp).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete OTP"})
-		return
-	}
-
 	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
 }
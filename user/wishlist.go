@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	db "admin/DB"
+	"admin/helper"
 	"admin/middleware"
 	"admin/models"
 	"admin/models/responsemodels"
@@ -56,7 +57,7 @@ func AddToWhishlist(c *gin.Context) {
 		return
 	}
 
-	if err := db.Db.Where("product_id=?", input.ProductID).First(&product).Error; err != nil {
+	if err := db.Db.Scopes(helper.Published).Where("product_id=?", input.ProductID).First(&product).Error; err != nil {
 		log.WithFields(log.Fields{
 			"ProductID": input.ProductID,
 		}).Error("Can't find the product")